@@ -0,0 +1,262 @@
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options is the CORS policy of a router or a group
+type Options struct {
+	// AllowedOrigins accepts exact origins ("https://app.example.com"),
+	// wildcard subdomains ("https://*.example.com") or "*" for any origin
+	AllowedOrigins []string
+
+	// AllowOriginFunc is asked when the origin is not in AllowedOrigins
+	AllowOriginFunc func(origin string, r *http.Request) bool
+
+	// AllowedMethods for preflight requests
+	// default GET, HEAD, POST, PUT, PATCH, DELETE
+	AllowedMethods []string
+
+	// AllowedHeaders the client may send, "*" allows any header
+	// default Accept, Authorization, Content-Type, X-Request-ID
+	AllowedHeaders []string
+
+	// ExposedHeaders the browser lets the client read
+	ExposedHeaders []string
+
+	// AllowCredentials lets the browser send cookies and auth headers, the
+	// allowed origin is echoed and Vary: Origin set. New panics when it is
+	// combined with "*" in AllowedOrigins, that would let every site make
+	// credentialed requests, list the origins or use AllowOriginFunc.
+	AllowCredentials bool
+
+	// MaxAge is how long the browser may cache a preflight response,
+	// 0 leaves the browser default
+	MaxAge time.Duration
+
+	// OptionsPassthrough calls the next handler after a preflight instead of
+	// answering 204
+	OptionsPassthrough bool
+}
+
+// Cors applies one Options policy
+type Cors struct {
+	allowAll         bool
+	origins          map[string]struct{}
+	wildcards        []wildcard
+	originFunc       func(origin string, r *http.Request) bool
+	methods          map[string]struct{}
+	methodsList      string
+	headers          map[string]struct{}
+	allowAllHeaders  bool
+	exposed          string
+	allowCredentials bool
+	maxAge           string
+	passthrough      bool
+}
+
+// wildcard origin split around '*', e.g. "https://" and ".example.com"
+type wildcard struct {
+	prefix string
+	suffix string
+}
+
+func (w wildcard) match(origin string) bool {
+	return len(origin) > len(w.prefix)+len(w.suffix) &&
+		strings.HasPrefix(origin, w.prefix) &&
+		strings.HasSuffix(origin, w.suffix)
+}
+
+// New creates a CORS policy, it panics for "*" with AllowCredentials
+func New(opts Options) *Cors {
+	c := &Cors{
+		origins:          make(map[string]struct{}),
+		originFunc:       opts.AllowOriginFunc,
+		methods:          make(map[string]struct{}),
+		headers:          make(map[string]struct{}),
+		allowCredentials: opts.AllowCredentials,
+		passthrough:      opts.OptionsPassthrough,
+	}
+
+	for _, o := range opts.AllowedOrigins {
+		o = strings.ToLower(strings.TrimSpace(o))
+		switch {
+		case o == "*":
+			if opts.AllowCredentials {
+				panic(`cors: AllowedOrigins "*" cannot be used with AllowCredentials, list the origins or use AllowOriginFunc`)
+			}
+			c.allowAll = true
+		case strings.Contains(o, "*"):
+			i := strings.IndexByte(o, '*')
+			c.wildcards = append(c.wildcards, wildcard{prefix: o[:i], suffix: o[i+1:]})
+		case o != "":
+			c.origins[o] = struct{}{}
+		}
+	}
+
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	list := make([]string, 0, len(methods))
+	for _, m := range methods {
+		m = strings.ToUpper(strings.TrimSpace(m))
+		if _, ok := c.methods[m]; ok || m == "" {
+			continue
+		}
+		c.methods[m] = struct{}{}
+		list = append(list, m)
+	}
+	c.methodsList = strings.Join(list, ", ")
+
+	headers := opts.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Accept", "Authorization", "Content-Type", "X-Request-ID"}
+	}
+	for _, h := range headers {
+		h = strings.TrimSpace(h)
+		if h == "*" {
+			c.allowAllHeaders = true
+			continue
+		}
+		c.headers[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+
+	if len(opts.ExposedHeaders) > 0 {
+		exposed := make([]string, 0, len(opts.ExposedHeaders))
+		for _, h := range opts.ExposedHeaders {
+			exposed = append(exposed, http.CanonicalHeaderKey(strings.TrimSpace(h)))
+		}
+		c.exposed = strings.Join(exposed, ", ")
+	}
+
+	if opts.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(opts.MaxAge / time.Second))
+	}
+	return c
+}
+
+// Middleware is a shortcut for New(opts).Handler
+//
+// Example:
+//
+//	r.Use(cors.Middleware(cors.Options{AllowedOrigins: []string{"https://*.example.com"}}))
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	return New(opts).Handler
+}
+
+// Handler answers preflight requests and adds the CORS headers to the others
+func (c *Cors) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			c.preflight(w, r)
+			if c.passthrough {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		c.actual(w, r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// echoOrigin reports whether the origin is sent back instead of "*",
+// the response then depends on Origin and caches must know it
+func (c *Cors) echoOrigin() bool {
+	return !c.allowAll
+}
+
+func (c *Cors) preflight(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	h.Add("Vary", "Origin")
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	origin := r.Header.Get("Origin")
+	if origin == "" || !c.originAllowed(origin, r) {
+		return
+	}
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	if _, ok := c.methods[method]; !ok {
+		return
+	}
+	requested := r.Header.Values("Access-Control-Request-Headers")
+	if !c.headersAllowed(requested) {
+		return
+	}
+
+	c.setOrigin(h, origin)
+	h.Set("Access-Control-Allow-Methods", c.methodsList)
+	if len(requested) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+	if c.maxAge != "" {
+		h.Set("Access-Control-Max-Age", c.maxAge)
+	}
+}
+
+func (c *Cors) actual(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	if c.echoOrigin() {
+		h.Add("Vary", "Origin")
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" || !c.originAllowed(origin, r) {
+		return
+	}
+	c.setOrigin(h, origin)
+	if c.exposed != "" {
+		h.Set("Access-Control-Expose-Headers", c.exposed)
+	}
+}
+
+func (c *Cors) setOrigin(h http.Header, origin string) {
+	if c.echoOrigin() {
+		h.Set("Access-Control-Allow-Origin", origin)
+	} else {
+		h.Set("Access-Control-Allow-Origin", "*")
+	}
+	if c.allowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (c *Cors) originAllowed(origin string, r *http.Request) bool {
+	if c.allowAll {
+		return true
+	}
+	o := strings.ToLower(origin)
+	if _, ok := c.origins[o]; ok {
+		return true
+	}
+	for _, w := range c.wildcards {
+		if w.match(o) {
+			return true
+		}
+	}
+	return c.originFunc != nil && c.originFunc(origin, r)
+}
+
+// headersAllowed checks the comma separated Access-Control-Request-Headers values
+func (c *Cors) headersAllowed(values []string) bool {
+	if c.allowAllHeaders {
+		return true
+	}
+	for _, v := range values {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := c.headers[http.CanonicalHeaderKey(name)]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
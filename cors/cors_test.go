@@ -0,0 +1,185 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var next = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusTeapot)
+})
+
+func serve(opts Options, method, origin string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	Middleware(opts)(next).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOrigins(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		origin string
+		allow  string
+		vary   bool
+	}{
+		{"exact", Options{AllowedOrigins: []string{"https://app.example.com"}}, "https://app.example.com", "https://app.example.com", true},
+		{"exact case insensitive", Options{AllowedOrigins: []string{"https://App.Example.com"}}, "https://app.example.com", "https://app.example.com", true},
+		{"not listed", Options{AllowedOrigins: []string{"https://app.example.com"}}, "https://evil.com", "", true},
+		{"wildcard subdomain", Options{AllowedOrigins: []string{"https://*.example.com"}}, "https://api.example.com", "https://api.example.com", true},
+		{"wildcard needs a subdomain", Options{AllowedOrigins: []string{"https://*.example.com"}}, "https://.example.com", "", true},
+		{"wildcard other scheme", Options{AllowedOrigins: []string{"https://*.example.com"}}, "http://api.example.com", "", true},
+		// any origin answers "*" and does not depend on Origin
+		{"any", Options{AllowedOrigins: []string{"*"}}, "https://x.com", "*", false},
+		{"func", Options{AllowOriginFunc: func(o string, _ *http.Request) bool { return o == "https://ok.com" }}, "https://ok.com", "https://ok.com", true},
+		{"no origin", Options{AllowedOrigins: []string{"https://app.example.com"}}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.opts, http.MethodGet, tt.origin, nil)
+			if rec.Code != http.StatusTeapot {
+				t.Fatalf("next handler not called, status %d", rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allow {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.allow)
+			}
+			if got := hasVary(rec.Header(), "Origin"); got != tt.vary {
+				t.Errorf("Vary: Origin = %v, want %v", got, tt.vary)
+			}
+		})
+	}
+}
+
+func TestCredentialsAndExposed(t *testing.T) {
+	rec := serve(Options{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowCredentials: true,
+		ExposedHeaders:   []string{"x-set-corelation-id", "Link"},
+	}, http.MethodGet, "https://app.example.com", nil)
+
+	h := rec.Header()
+	if got := h.Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Allow-Credentials = %q", got)
+	}
+	if got := h.Get("Access-Control-Expose-Headers"); got != "X-Set-Corelation-Id, Link" {
+		t.Errorf("Expose-Headers = %q", got)
+	}
+
+	// nothing is sent for a refused origin
+	rec = serve(Options{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true}, http.MethodGet, "https://evil.com", nil)
+	for _, name := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Credentials"} {
+		if v := rec.Header().Get(name); v != "" {
+			t.Errorf("refused origin got %s: %q", name, v)
+		}
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	opts := Options{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "post"},
+		AllowedHeaders: []string{"Content-Type", "X-Api-Key"},
+		MaxAge:         10 * time.Minute,
+	}
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		allowed bool
+	}{
+		{"allowed", "https://app.example.com", "POST", "content-type, x-api-key", true},
+		{"no request headers", "https://app.example.com", "GET", "", true},
+		{"method refused", "https://app.example.com", "DELETE", "", false},
+		{"header refused", "https://app.example.com", "POST", "Authorization", false},
+		{"origin refused", "https://evil.com", "POST", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := map[string]string{"Access-Control-Request-Method": tt.method}
+			if tt.headers != "" {
+				header["Access-Control-Request-Headers"] = tt.headers
+			}
+			rec := serve(opts, http.MethodOptions, tt.origin, header)
+
+			// answered by the middleware, the next handler is not called
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("status = %d, want 204", rec.Code)
+			}
+			h := rec.Header()
+			want := []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}
+			if got := h.Values("Vary"); !reflect.DeepEqual(got, want) {
+				t.Errorf("Vary = %v, want %v", got, want)
+			}
+			if !tt.allowed {
+				if v := h.Get("Access-Control-Allow-Origin"); v != "" {
+					t.Errorf("refused preflight got Allow-Origin %q", v)
+				}
+				return
+			}
+			if v := h.Get("Access-Control-Allow-Origin"); v != tt.origin {
+				t.Errorf("Allow-Origin = %q", v)
+			}
+			if v := h.Get("Access-Control-Allow-Methods"); v != "GET, POST" {
+				t.Errorf("Allow-Methods = %q", v)
+			}
+			if v := h.Get("Access-Control-Allow-Headers"); v != tt.headers {
+				t.Errorf("Allow-Headers = %q, want %q", v, tt.headers)
+			}
+			if v := h.Get("Access-Control-Max-Age"); v != "600" {
+				t.Errorf("Max-Age = %q", v)
+			}
+		})
+	}
+}
+
+func TestPreflightPassthrough(t *testing.T) {
+	rec := serve(Options{AllowedOrigins: []string{"*"}, OptionsPassthrough: true}, http.MethodOptions,
+		"https://x.com", map[string]string{"Access-Control-Request-Method": "GET"})
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("status = %d, want the next handler", rec.Code)
+	}
+	if v := rec.Header().Get("Access-Control-Allow-Origin"); v != "*" {
+		t.Errorf("Allow-Origin = %q", v)
+	}
+}
+
+// a plain OPTIONS request is not a preflight
+func TestOptionsWithoutPreflight(t *testing.T) {
+	rec := serve(Options{AllowedOrigins: []string{"*"}}, http.MethodOptions, "https://x.com", nil)
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("status = %d, want the next handler", rec.Code)
+	}
+	if v := rec.Header().Get("Access-Control-Allow-Methods"); v != "" {
+		t.Errorf("Allow-Methods = %q on a plain OPTIONS", v)
+	}
+}
+
+func hasVary(h http.Header, name string) bool {
+	for _, v := range h.Values("Vary") {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// "*" with credentials would let any site read credentialed responses
+func TestAnyOriginWithCredentialsPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("New did not panic")
+		}
+	}()
+	New(Options{AllowedOrigins: []string{"https://app.example.com", " * "}, AllowCredentials: true})
+}
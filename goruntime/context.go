@@ -0,0 +1,18 @@
+package goruntime
+
+import "context"
+
+type corelationKey struct{}
+
+// NewContext returns a copy of ctx carrying the correlation id,
+// the id follows the request into every goroutine that receives ctx
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, corelationKey{}, id)
+}
+
+// FromContext returns the correlation id stored in ctx, empty if there is none
+// Usage: id := goruntime.FromContext(r.Context())
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(corelationKey{}).(string)
+	return id
+}
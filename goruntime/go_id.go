@@ -24,24 +24,45 @@ func Goid() int64 {
 	return id
 }
 
+// GetCorelationID returns the id bound to the current goroutine. When there
+// is none a new UUID is generated and bound, as before the router stored the
+// id in the request context. An id that is not a UUID gives uuid.Nil.
+//
+// Deprecated: the id is lost in goroutines started by the handler and the
+// router only binds it with CorelationConfig.LegacyGoroutineBinding,
+// use FromContext(r.Context()).
 func GetCorelationID() uuid.UUID {
 	gid := Goid()
-	stockReqID, ok := reqID.Load(gid)
-	if ok {
-		return stockReqID.(uuid.UUID)
+	stored, ok := reqID.Load(gid)
+	if !ok {
+		newID := uuid.New()
+		reqID.Store(gid, newID.String())
+		return newID
 	}
-	newID := uuid.New()
-	reqID.Store(gid, newID)
-	return newID
+	id, err := uuid.Parse(stored.(string))
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
 }
 
 // Set Request ID to aware for global logger
+//
+// Deprecated: use NewContext.
 func SetCorelationID(id uuid.UUID) {
-	gid := Goid()
-	reqID.Store(gid, id)
+	reqID.Store(Goid(), id.String())
 }
 
+// Deprecated: ids stored with NewContext end with the request context.
 func ClearCorelationID() {
+	reqID.Delete(Goid())
+}
+
+// BindGoroutine stores id for the current goroutine until unbind is called,
+// the router uses it with CorelationConfig.LegacyGoroutineBinding so
+// GetCorelationID keeps working in handlers
+func BindGoroutine(id string) (unbind func()) {
 	gid := Goid()
-	reqID.Delete(gid)
+	reqID.Store(gid, id)
+	return func() { reqID.Delete(gid) }
 }
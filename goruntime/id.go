@@ -0,0 +1,55 @@
+package goruntime
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator creates a new correlation id
+type IDGenerator func() string
+
+// NewUUIDv4 returns a random UUID, e.g. "0b8f6d2e-8a55-4c2b-9a53-6a1f0f5f3c1d"
+func NewUUIDv4() string {
+	return uuid.NewString()
+}
+
+// NewUUIDv7 returns a time ordered UUID, falls back to UUIDv4 when the
+// random source fails
+func NewUUIDv7() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+// crockford base32 alphabet used by ULID
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a ULID, 26 characters sortable by creation time,
+// e.g. "01J9Z3Q4X8V6M2T5R7K9N1B3C4"
+func NewULID() string {
+	var b [16]byte
+	ms := uint64(time.Now().UnixMilli())
+	// 48 bit timestamp then 80 bit randomness
+	binary.BigEndian.PutUint16(b[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+	if _, err := rand.Read(b[6:]); err != nil {
+		u := uuid.New()
+		copy(b[6:], u[:10])
+	}
+
+	// 128 bits as 26 base32 characters, the first one holds 3 bits
+	var out [26]byte
+	hi := binary.BigEndian.Uint64(b[0:8])
+	lo := binary.BigEndian.Uint64(b[8:16])
+	for i := 25; i >= 0; i-- {
+		out[i] = ulidAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
@@ -0,0 +1,66 @@
+package goruntime
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewUUIDv7(t *testing.T) {
+	before := time.Now().Add(-time.Millisecond)
+	first := NewUUIDv7()
+	id, err := uuid.Parse(first)
+	if err != nil {
+		t.Fatalf("NewUUIDv7 = %q: %v", first, err)
+	}
+	if id.Version() != 7 || id.Variant() != uuid.RFC4122 {
+		t.Errorf("%s is version %d variant %s", id, id.Version(), id.Variant())
+	}
+	sec, nsec := id.Time().UnixTime()
+	if at := time.Unix(sec, nsec); at.Before(before) || at.After(time.Now().Add(time.Millisecond)) {
+		t.Errorf("time of %s = %s, want now", id, at)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	if next := NewUUIDv7(); next <= first {
+		t.Errorf("%s generated after %s sorts before it", next, first)
+	}
+}
+
+func TestNewULID(t *testing.T) {
+	before := time.Now().UnixMilli()
+	first := NewULID()
+	after := time.Now().UnixMilli()
+
+	if len(first) != 26 {
+		t.Fatalf("NewULID = %q, want 26 characters", first)
+	}
+	for _, c := range first {
+		if !strings.ContainsRune(ulidAlphabet, c) {
+			t.Fatalf("NewULID = %q has %q outside the alphabet", first, c)
+		}
+	}
+	// 128 bits, the first character holds the top 3
+	if first[0] > '7' {
+		t.Errorf("NewULID = %q overflows 128 bits", first)
+	}
+
+	// the first 10 characters are the millisecond timestamp
+	var ms int64
+	for _, c := range first[:10] {
+		ms = ms<<5 | int64(strings.IndexRune(ulidAlphabet, c))
+	}
+	if ms < before || ms > after {
+		t.Errorf("timestamp of %s = %d, want between %d and %d", first, ms, before, after)
+	}
+
+	if other := NewULID(); other == first {
+		t.Errorf("NewULID repeated %s", first)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if next := NewULID(); next <= first {
+		t.Errorf("%s generated after %s sorts before it", next, first)
+	}
+}
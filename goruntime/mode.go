@@ -0,0 +1,82 @@
+package goruntime
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Mode is the runtime mode shared by the logger, the responses and the router
+type Mode string
+
+const (
+	// ModeDev logs human readable output and shows error details to clients
+	ModeDev Mode = "dev"
+	// ModeProd logs JSON and redacts the details of 5xx responses
+	ModeProd Mode = "prod"
+)
+
+// EnvMode is the environment variable read for the initial mode,
+// "dev"/"development" or "prod"/"production". Without it the process runs
+// in ModeProd, dev is opt-in
+const EnvMode = "SIMPROUTE_MODE"
+
+var mode atomic.Value
+
+func init() {
+	mode.Store(ModeFromEnv())
+}
+
+// ParseMode returns the mode named by s, ok is false when s names none
+func ParseMode(s string) (m Mode, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "dev", "development":
+		return ModeDev, true
+	case "prod", "production":
+		return ModeProd, true
+	}
+	return ModeProd, false
+}
+
+// ModeFromEnv returns the mode set in SIMPROUTE_MODE, ModeProd when it is
+// empty or unknown
+func ModeFromEnv() Mode {
+	m, _ := ParseMode(os.Getenv(EnvMode))
+	return m
+}
+
+// SetMode changes the runtime mode of the process, routers already made
+// keep their own Mode
+// Usage: goruntime.SetMode(goruntime.ModeDev)
+func SetMode(m Mode) {
+	mode.Store(m)
+}
+
+// CurrentMode returns the runtime mode of the process
+func CurrentMode() Mode {
+	return mode.Load().(Mode)
+}
+
+// IsDev reports whether the process runs in ModeDev
+func IsDev() bool {
+	return CurrentMode() == ModeDev
+}
+
+type modeKey struct{}
+
+// NewModeContext returns a copy of ctx carrying the mode m, the router
+// stores its Mode in the context of every request
+func NewModeContext(ctx context.Context, m Mode) context.Context {
+	return context.WithValue(ctx, modeKey{}, m)
+}
+
+// ModeFromContext returns the mode stored in ctx, the mode of the process
+// if there is none
+// Usage: dev := goruntime.ModeFromContext(r.Context()) == goruntime.ModeDev
+func ModeFromContext(ctx context.Context) Mode {
+	if m, ok := ctx.Value(modeKey{}).(Mode); ok {
+		return m
+	}
+	return CurrentMode()
+}
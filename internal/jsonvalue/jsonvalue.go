@@ -0,0 +1,82 @@
+// Package jsonvalue decodes JSON into generic values keeping the order of
+// object members, for the encoders writing the JSON form of a value in
+// another format
+package jsonvalue
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// Field is one member of a decoded JSON object
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Decode decodes data into nil, bool, json.Number, string, []any or
+// []Field, the members of an object keep their order
+func Decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decode(dec)
+}
+
+// Of decodes the JSON form of v, see Decode
+func Of(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(b)
+}
+
+func decode(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := []Field{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decode(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, Field{Key: key.(string), Value: v})
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			v, err := decode(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err = dec.Token()
+		return arr, err
+	}
+	return tok, nil
+}
+
+// Number converts n into an int64, an uint64 when it is above the int64
+// range, else a float64
+func Number(n json.Number) any {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return u
+	}
+	f, _ := n.Float64()
+	return f
+}
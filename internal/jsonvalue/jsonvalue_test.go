@@ -0,0 +1,57 @@
+package jsonvalue
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	got, err := Decode([]byte(`{"b":1.50,"a":[1,null,true,"x"],"c":{}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Field{
+		{"b", json.Number("1.50")},
+		{"a", []any{json.Number("1"), nil, true, "x"}},
+		{"c", []Field{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode = %#v, want %#v", got, want)
+	}
+
+	if _, err := Decode([]byte(`{"a":`)); err == nil {
+		t.Error("truncated document decoded without error")
+	}
+}
+
+func TestOf(t *testing.T) {
+	got, err := Of(struct {
+		Z string `json:"z"`
+		A int    `json:"a,omitempty"`
+	}{Z: "z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Field{{"z", "z"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Of = %#v, want %#v", got, want)
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		n    json.Number
+		want any
+	}{
+		{"42", int64(42)},
+		{"-7", int64(-7)},
+		{"18446744073709551615", uint64(18446744073709551615)},
+		{"1.5", 1.5},
+		{"1e3", 1000.0},
+	}
+	for _, tt := range tests {
+		if got := Number(tt.n); got != tt.want {
+			t.Errorf("Number(%s) = %#v, want %#v", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/he-end/simproute/routes"
+	"github.com/he-end/simproute/routes/response"
+)
+
+// Config is the document level information of Generate
+type Config struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []Server
+
+	SecuritySchemes map[string]SecurityScheme
+	// security applied to every operation without its own
+	Security []map[string][]string
+
+	// document the response types as is, by default they are the data of
+	// the response.Response envelope
+	NoEnvelope bool
+}
+
+var envelopeType = reflect.TypeOf(response.Response{})
+
+// Generate builds the document of the routes registered on r,
+// the automatic HEAD and OPTIONS answers and Hidden routes are left out
+//
+// Example:
+//
+//	doc := openapi.Generate(r, openapi.Config{Title: "Users API", Version: "1.0.0"})
+//	b, err := doc.JSON()
+func Generate(r *routes.Router, cfg Config) *Document {
+	doc := &Document{
+		OpenAPI:  Version,
+		Info:     Info{Title: cfg.Title, Version: cfg.Version, Description: cfg.Description},
+		Servers:  cfg.Servers,
+		Paths:    make(map[string]PathItem),
+		Security: cfg.Security,
+	}
+	s := newSchemas()
+
+	var infos []routes.RouteInfo
+	methods := make(map[string]int) // route name -> number of methods
+	r.Walk(func(ri routes.RouteInfo) error {
+		if !ri.Operation.Hidden {
+			infos = append(infos, ri)
+			methods[ri.Name]++
+		}
+		return nil
+	})
+	for _, ri := range infos {
+		item := doc.Paths[ri.Template]
+		if item == nil {
+			item = make(PathItem)
+			doc.Paths[ri.Template] = item
+		}
+		op := operation(s, ri, cfg)
+		// operationIds are unique, a name shared by several methods gets
+		// the method appended, e.g. user_put
+		if ri.Name != "" && methods[ri.Name] > 1 {
+			op.OperationID = ri.Name + "_" + strings.ToLower(ri.Method)
+		}
+		item[strings.ToLower(ri.Method)] = op
+	}
+
+	if len(s.components) > 0 || len(cfg.SecuritySchemes) > 0 {
+		doc.Components = &Components{Schemas: s.components, SecuritySchemes: cfg.SecuritySchemes}
+	}
+	return doc
+}
+
+func operation(s *schemas, ri routes.RouteInfo, cfg Config) *Operation {
+	meta := ri.Operation
+	op := &Operation{
+		OperationID: ri.Name,
+		Summary:     meta.Summary,
+		Description: meta.Description,
+		Tags:        meta.Tags,
+		Responses:   make(map[string]*Response),
+		Security:    meta.Security,
+		Deprecated:  meta.Deprecated,
+	}
+
+	for i, name := range ri.ParamNames {
+		p := &Parameter{Name: name, In: "path", Required: true, Schema: paramSchema(ri.ParamConstraints[i])}
+		if ri.ParamCatchAll[i] {
+			p.Description = "rest of the path, may contain /"
+		}
+		op.Parameters = append(op.Parameters, p)
+	}
+
+	if meta.Request != nil {
+		params, body := requestParts(s, reflect.TypeOf(meta.Request))
+		op.Parameters = append(op.Parameters, params...)
+		if body && hasBody(ri.Method) {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: s.of(meta.Request)}},
+			}
+		}
+	}
+
+	for status, v := range meta.Responses {
+		resp := &Response{Description: http.StatusText(status)}
+		if resp.Description == "" {
+			resp.Description = strconv.Itoa(status)
+		}
+		if v != nil {
+			resp.Content = map[string]MediaType{"application/json": {Schema: responseSchema(s, v, cfg)}}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["200"] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+	return op
+}
+
+// requestParts returns the query and header parameters of a request struct
+// read by binding.Bind, and whether it has fields read from the body
+func requestParts(s *schemas, t reflect.Type) ([]*Parameter, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, true
+	}
+
+	var params []*Parameter
+	body := false
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			p, b := requestParts(s, f.Type)
+			params, body = append(params, p...), body || b
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		required := hasRule(f.Tag.Get("validate"), "required")
+		if name := f.Tag.Get("query"); name != "" {
+			params = append(params, &Parameter{Name: name, In: "query", Required: required, Schema: paramFieldSchema(s, f)})
+		}
+		if name := f.Tag.Get("header"); name != "" {
+			params = append(params, &Parameter{Name: name, In: "header", Required: required, Schema: paramFieldSchema(s, f)})
+		}
+		if isBodyField(f) {
+			body = true
+		}
+	}
+	return params, body
+}
+
+// paramFieldSchema returns the schema of a query or header field with the
+// value of its default tag
+func paramFieldSchema(s *schemas, f reflect.StructField) *Schema {
+	schema := s.schemaOf(f.Type)
+	if def, ok := f.Tag.Lookup("default"); ok {
+		schema.Default = defaultValue(schema, def)
+	}
+	return schema
+}
+
+// defaultValue converts the text of a default tag to the type of schema,
+// binding gives a slice the default as its only item
+func defaultValue(schema *Schema, text string) any {
+	switch schema.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(text, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	case "array":
+		if schema.Items != nil {
+			return []any{defaultValue(schema.Items, text)}
+		}
+	}
+	return text
+}
+
+// isBodyField reports whether binding.Bind reads f from a JSON body
+func isBodyField(f reflect.StructField) bool {
+	if f.Tag.Get("json") == "-" {
+		return false
+	}
+	if f.Tag.Get("json") != "" {
+		return true
+	}
+	return f.Tag.Get("path") == "" && f.Tag.Get("query") == "" && f.Tag.Get("header") == ""
+}
+
+func hasRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func hasBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// responseSchema wraps the schema of v as data of the response envelope
+func responseSchema(s *schemas, v any, cfg Config) *Schema {
+	t := reflect.TypeOf(v)
+	if cfg.NoEnvelope || t == envelopeType {
+		return s.of(v)
+	}
+	// a page is sent as its items, with meta.page
+	if p, ok := v.(response.Pager); ok {
+		v = p.PageItems()
+	}
+	return &Schema{AllOf: []*Schema{
+		s.schemaOf(envelopeType),
+		{Type: "object", Properties: map[string]*Schema{"data": s.of(v)}},
+	}}
+}
+
+// paramSchema maps a route constraint onto a schema
+func paramSchema(expr string) *Schema {
+	switch expr {
+	case "":
+		return &Schema{Type: "string"}
+	case "int":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "uint":
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	case "date":
+		return &Schema{Type: "string", Format: "date"}
+	case "alpha":
+		return &Schema{Type: "string", Pattern: "^[A-Za-z]+$"}
+	}
+	if isName(expr) {
+		// type added with routes.RegisterConstraint
+		return &Schema{Type: "string", Description: expr}
+	}
+	return &Schema{Type: "string", Pattern: "^(?:" + expr + ")$"}
+}
+
+func isName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// JSON returns the indented JSON document
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// YAML returns the YAML document, keys keep the JSON order
+func (d *Document) YAML() ([]byte, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYAML(b)
+}
@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/he-end/simproute/goruntime"
+	"github.com/he-end/simproute/routes"
+	"github.com/he-end/simproute/routes/response"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestMain(m *testing.M) {
+	// routes.New in prod would write logs/app.log here
+	goruntime.SetMode(goruntime.ModeDev)
+	os.Exit(m.Run())
+}
+
+type Audit struct {
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type User struct {
+	Audit
+	ID    int64    `json:"id"`
+	Name  string   `json:"name"`
+	Email string   `json:"email,omitempty"`
+	Tags  []string `json:"tags"`
+	// not documented
+	password string
+}
+
+type CreateUser struct {
+	Tenant string   `header:"X-Tenant" validate:"required"`
+	Notify bool     `query:"notify" default:"true"`
+	Page   int      `query:"page" default:"1"`
+	Ratio  float64  `query:"ratio" default:"0.5"`
+	Sort   []string `query:"sort" default:"name"`
+	Name   string   `json:"name" validate:"required"`
+	Email  string   `json:"email"`
+}
+
+func testRouter() *routes.Router {
+	r := routes.New()
+	r.POST("/orgs/{org:[a-z]+}/users", routes.Typed(func(ctx context.Context, req CreateUser) (User, error) {
+		return User{}, nil
+	})).Name("createUser").Summary("Create a user").Tags("users").
+		Body(CreateUser{}).Response(http.StatusCreated, User{}).Response(http.StatusConflict, nil)
+	r.Handle([]string{http.MethodGet, http.MethodPut}, "/users/{id:int}", func(w http.ResponseWriter, req *http.Request) {}).
+		Name("user").Response(http.StatusOK, User{})
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {}).
+		Response(http.StatusOK, response.Page[User]{})
+	r.Get("/files/*path", func(w http.ResponseWriter, req *http.Request) {}).Name("file")
+	r.Get("/days/{day:date}/{id:uuid}", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/internal", func(w http.ResponseWriter, req *http.Request) {}).Hidden()
+	return r
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run go test ./openapi -update", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the generated document:\n%s", path, got)
+	}
+}
+
+func TestGenerateGolden(t *testing.T) {
+	doc := Generate(testRouter(), Config{Title: "Users API", Version: "1.0.0"})
+	b, err := doc.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "users.json", append(b, '\n'))
+
+	y, err := doc.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "users.yaml", y)
+}
+
+func TestOperationIDs(t *testing.T) {
+	doc := Generate(testRouter(), Config{})
+	seen := map[string]string{}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			if op.OperationID == "" {
+				continue
+			}
+			if other, ok := seen[op.OperationID]; ok {
+				t.Errorf("operationId %s used by %s and %s %s", op.OperationID, other, method, path)
+			}
+			seen[op.OperationID] = method + " " + path
+		}
+	}
+	for id, want := range map[string]string{
+		"user_get":   "get /users/{id}",
+		"user_put":   "put /users/{id}",
+		"createUser": "post /orgs/{org}/users",
+		"file":       "get /files/{path}",
+	} {
+		if seen[id] != want {
+			t.Errorf("operationId %s = %q, want %q", id, seen[id], want)
+		}
+	}
+}
+
+func TestParameterDefaults(t *testing.T) {
+	doc := Generate(testRouter(), Config{})
+	op := doc.Paths["/orgs/{org}/users"]["post"]
+	got := map[string]any{}
+	for _, p := range op.Parameters {
+		got[p.Name] = p.Schema.Default
+	}
+	b, _ := json.Marshal(got)
+	want := `{"X-Tenant":null,"notify":true,"org":null,"page":1,"ratio":0.5,"sort":["name"]}`
+	if string(b) != want {
+		t.Errorf("defaults = %s, want %s", b, want)
+	}
+}
+
+func TestYAMLScalars(t *testing.T) {
+	b, err := jsonToYAML([]byte(`{"plain":"users","num":"1.0","bool":"yes","null":"null","empty":"","colon":"a: b","hash":"#x","comment":"a #b","lead":" x","dash":"-x","ref":"#/components/schemas/User","path":"/users/{id}","n":1.50,"list":[],"obj":{}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `plain: users
+num: "1.0"
+bool: "yes"
+"null": "null"
+empty: ""
+colon: "a: b"
+hash: "#x"
+comment: "a #b"
+lead: " x"
+dash: "-x"
+ref: "#/components/schemas/User"
+path: /users/{id}
+"n": 1.50
+list: []
+obj: {}
+`
+	if string(b) != want {
+		t.Errorf("YAML =\n%s\nwant\n%s", b, want)
+	}
+}
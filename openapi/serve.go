@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/he-end/simproute/routes"
+	"github.com/he-end/simproute/routes/response"
+)
+
+// Handler serves the document of r, generated on the first request.
+// It answers YAML when the path ends with .yaml or .yml, JSON otherwise.
+func Handler(r *routes.Router, cfg Config) http.Handler {
+	var (
+		once      sync.Once
+		jsonDoc   []byte
+		yamlDoc   []byte
+		errRender error
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		once.Do(func() {
+			doc := Generate(r, cfg)
+			if jsonDoc, errRender = doc.JSON(); errRender == nil {
+				yamlDoc, errRender = doc.YAML()
+			}
+		})
+		if errRender != nil {
+			response.For(req).Error(w, "Internal server error", response.ErrCodeInternalError, errRender.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if strings.HasSuffix(req.URL.Path, ".yaml") || strings.HasSuffix(req.URL.Path, ".yml") {
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write(yamlDoc)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(jsonDoc)
+	})
+}
+
+// Register adds a GET route serving the document of r on path, the route
+// itself is hidden from the document
+//
+// Example:
+//
+//	openapi.Register(r, "/openapi.json", openapi.Config{Title: "Users API", Version: "1.0.0"})
+func Register(r *routes.Router, path string, cfg Config) *routes.Route {
+	return r.Get(path, Handler(r, cfg).ServeHTTP).Hidden()
+}
@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/he-end/simproute/internal/jsonvalue"
+)
+
+// jsonToYAML converts a JSON document into block style YAML
+func jsonToYAML(data []byte) ([]byte, error) {
+	v, err := jsonvalue.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	writeYAML(&b, v, 0)
+	return b.Bytes(), nil
+}
+
+func writeYAML(b *bytes.Buffer, v any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case []jsonvalue.Field:
+		for _, f := range v {
+			b.WriteString(pad + yamlString(f.Key) + ":")
+			writeNested(b, f.Value, indent)
+		}
+	case []any:
+		for _, item := range v {
+			// an object item starts on the line of its dash
+			if obj, ok := item.([]jsonvalue.Field); ok && len(obj) > 0 {
+				var item bytes.Buffer
+				writeYAML(&item, obj, indent+1)
+				b.WriteString(pad + "- ")
+				b.Write(item.Bytes()[len(pad)+2:])
+				continue
+			}
+			b.WriteString(pad + "-")
+			writeNested(b, item, indent)
+		}
+	}
+}
+
+// writeNested writes the value of a key or list item, collections go on
+// the next lines one level deeper
+func writeNested(b *bytes.Buffer, v any, indent int) {
+	switch c := v.(type) {
+	case []jsonvalue.Field:
+		if len(c) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteByte('\n')
+		writeYAML(b, c, indent+1)
+	case []any:
+		if len(c) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteByte('\n')
+		writeYAML(b, c, indent+1)
+	default:
+		b.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return v.String()
+	case string:
+		return yamlString(v)
+	}
+	return ""
+}
+
+// yamlString writes s plain when it cannot be read as another type or
+// as YAML syntax, otherwise double quoted (JSON escapes are valid YAML)
+func yamlString(s string) string {
+	plain := s != ""
+	for i := 0; i < len(s) && plain; i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == '/', c == '$':
+		case c >= '0' && c <= '9', c == '.', c == '-', c == '{', c == '}', c == '#':
+			plain = i > 0
+		case c == ' ':
+			// " #" starts a comment
+			plain = i > 0 && i < len(s)-1 && s[i+1] != '#' && s[i+1] != ' '
+		default:
+			plain = false
+		}
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+		plain = false
+	}
+	if plain {
+		return s
+	}
+	q, _ := json.Marshal(s)
+	return string(q)
+}
@@ -19,7 +19,7 @@ func AddRateLimit(path string, capacity, refill int, interval time.Duration) fun
 					ip = r.RemoteAddr
 				}
 				if !rl.allow(ip) {
-					logger.GetLogger().Warn("Rate limit exceeded", zap.String("ip", ip), zap.String("path", r.URL.Path))
+					logger.Ctx(r.Context()).Warn("Rate limit exceeded", zap.String("ip", ip), zap.String("path", r.URL.Path))
 					w.WriteHeader(http.StatusTooManyRequests)
 					_, _ = w.Write([]byte("too many requests"))
 					return
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/he-end/simproute/goruntime"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -54,11 +56,9 @@ func InitLogger(env string, level string) (*zap.Logger, error) {
 		// config.EncodeLevel = zapcore.LowercaseLevelEncoder
 		// config.EncodeCaller = zapcore.ShortCallerEncoder
 
-		// Create logs directory if it doesn't exist
+		// lumberjack creates the logs directory on the first write, so a
+		// process that never logs leaves no directory behind
 		logsDir := "logs"
-		if err := os.MkdirAll(logsDir, 0755); err != nil {
-			return nil, err
-		}
 
 		// Configure lumberjack for log rotation
 		lumberjackLogger := &lumberjack.Logger{
@@ -92,11 +92,33 @@ func InitLogger(env string, level string) (*zap.Logger, error) {
 	return globalLogger, nil
 }
 
+// EnvLevel is the environment variable read by InitFromMode for the level
+const EnvLevel = "SIMPROUTE_LOG_LEVEL"
+
+// InitFromMode initializes the global logger for the runtime mode of
+// goruntime, the level comes from SIMPROUTE_LOG_LEVEL and defaults to
+// "debug" in dev and "info" in prod
+func InitFromMode() (*zap.Logger, error) {
+	mode := goruntime.CurrentMode()
+	level := os.Getenv(EnvLevel)
+	if level == "" {
+		level = "debug"
+		if mode == goruntime.ModeProd {
+			level = "info"
+		}
+	}
+	return InitLogger(string(mode), level)
+}
+
 // GetLogger returns the global logger instance
 func GetLogger() *zap.Logger {
 	if globalLogger == nil {
-		// Fallback to development logger if not initialized
-		globalLogger, _ = zap.NewDevelopment()
+		// Fallback to a logger of the runtime mode if not initialized
+		if goruntime.IsDev() {
+			globalLogger, _ = zap.NewDevelopment()
+		} else {
+			globalLogger, _ = zap.NewProduction()
+		}
 	}
 	return globalLogger
 }
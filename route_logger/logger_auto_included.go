@@ -2,12 +2,17 @@ package logger
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/he-end/simproute/goruntime"
+	"go.uber.org/zap"
 )
 
 var (
 	loggerRuntimesStore sync.Map
+	// number of goroutines in loggerRuntimesStore, lets the level functions
+	// skip the goroutine id lookup when nothing is registered
+	loggerRuntimesCount atomic.Int64
 )
 
 type RegisterRuntime struct {
@@ -19,18 +24,28 @@ type RegisterRuntime struct {
 
 // Generate logger for only this runtime
 //
-// The value is needed for corelation id for each level logger
+// # The value is needed for corelation id for each level logger
+//
+// Deprecated: the value is lost in goroutines started by the handler,
+// use WithFields on the request context and Ctx to log.
 func NewLoggerOnRuntime(reg RegisterRuntime) {
-	loggerRuntimesStore.Store(goruntime.Goid(), reg)
-	// globalLogger = globalLogger.WithOptions(zap.AddCallerSkip(1))
-	// defer loggerRuntimesStore.Clear()
+	if _, loaded := loggerRuntimesStore.Swap(goruntime.Goid(), reg); !loaded {
+		loggerRuntimesCount.Add(1)
+	}
 }
 
+// Deprecated: fields added with WithFields end with the request context.
 func DeferDeleteRuntimeValue() {
-	loggerRuntimesStore.Delete(goruntime.Goid())
+	if _, loaded := loggerRuntimesStore.LoadAndDelete(goruntime.Goid()); loaded {
+		loggerRuntimesCount.Add(-1)
+	}
 }
 
+// Deprecated: use Ctx.
 func GetLoggerRuntimeStore() *RegisterRuntime {
+	if loggerRuntimesCount.Load() == 0 {
+		return nil
+	}
 	store, ok := loggerRuntimesStore.Load(goruntime.Goid())
 	if !ok {
 		return nil
@@ -39,9 +54,10 @@ func GetLoggerRuntimeStore() *RegisterRuntime {
 	return &result
 }
 
-// Info()
-// Warn()
-// Error()
-// Fatal()
-// Panic()
-// }
+// withRuntimeValue appends the value registered with NewLoggerOnRuntime
+func withRuntimeValue(fields []zap.Field) []zap.Field {
+	if reg := GetLoggerRuntimeStore(); reg != nil {
+		fields = append(fields, zap.String(reg.Key, reg.Value))
+	}
+	return fields
+}
@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type ctxLoggerKey struct{}
+
+// WithFields returns a copy of ctx whose Ctx logger also writes fields,
+// fields already added to ctx are kept
+//
+// Example:
+//
+//	ctx = logger.WithFields(ctx, zap.String("user_id", id))
+//	logger.Ctx(ctx).Info("profile updated")
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	return context.WithValue(ctx, ctxLoggerKey{}, Ctx(ctx).With(fields...))
+}
+
+// Ctx returns the logger for ctx, it is the global logger with the request
+// fields (request_id, ...) added with WithFields
+func Ctx(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxLoggerKey{}).(*zap.Logger); ok {
+			return l
+		}
+	}
+	return GetLogger()
+}
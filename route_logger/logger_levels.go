@@ -1,74 +1,28 @@
 package logger
 
 import (
-	"github.com/he-end/simproute/goruntime"
 	"go.uber.org/zap"
 )
 
-func Info(message string, fields ...zap.Field) {
-	logSkipCaller := globalLogger.WithOptions(zap.AddCallerSkip(1))
-	store, ok := loggerRuntimesStore.Load(goruntime.Goid())
-	if !ok || store == nil {
-		logSkipCaller.Info(message, fields...)
-		return
-	}
+// Info, Warn, Error, Fatal and Panic log with the global logger.
+// For request logs use Ctx(r.Context()) so the request fields are included.
 
-	withValue := store.(RegisterRuntime)
-	zapRequestID := zap.String(withValue.Key, withValue.Value)
-	fields = append(fields, zapRequestID)
-	logSkipCaller.Info(message, fields...)
+func Info(message string, fields ...zap.Field) {
+	GetLogger().WithOptions(zap.AddCallerSkip(1)).Info(message, withRuntimeValue(fields)...)
 }
 
 func Warn(message string, fields ...zap.Field) {
-	logSkipCaller := globalLogger.WithOptions(zap.AddCallerSkip(1))
-	store, ok := loggerRuntimesStore.Load(goruntime.Goid())
-	if !ok || store == nil {
-		logSkipCaller.Warn(message, fields...)
-		return
-	}
-	withValue := store.(RegisterRuntime)
-	zapRequestID := zap.String(withValue.Key, withValue.Value)
-	fields = append(fields, zapRequestID)
-	logSkipCaller.Warn(message, fields...)
+	GetLogger().WithOptions(zap.AddCallerSkip(1)).Warn(message, withRuntimeValue(fields)...)
 }
 
 func Error(message string, fields ...zap.Field) {
-	logSkipCaller := globalLogger.WithOptions(zap.AddCallerSkip(1))
-	store, ok := loggerRuntimesStore.Load(goruntime.Goid())
-	if !ok || store == nil {
-		logSkipCaller.Error(message, fields...)
-		return
-	}
-	withValue := store.(RegisterRuntime)
-	zapRequestID := zap.String(withValue.Key, withValue.Value)
-	fields = append(fields, zapRequestID)
-	logSkipCaller.Error(message, fields...)
+	GetLogger().WithOptions(zap.AddCallerSkip(1)).Error(message, withRuntimeValue(fields)...)
 }
 
 func Fatal(message string, fields ...zap.Field) {
-	logSkipCaller := globalLogger.WithOptions(zap.AddCallerSkip(1))
-	store, ok := loggerRuntimesStore.Load(goruntime.Goid())
-	if !ok || store == nil {
-		logSkipCaller.Fatal(message, fields...)
-		return
-	}
-
-	withValue := store.(RegisterRuntime)
-	zapRequestID := zap.String(withValue.Key, withValue.Value)
-	fields = append(fields, zapRequestID)
-	logSkipCaller.Fatal(message, fields...)
+	GetLogger().WithOptions(zap.AddCallerSkip(1)).Fatal(message, withRuntimeValue(fields)...)
 }
 
 func Panic(message string, fields ...zap.Field) {
-	logSkipCaller := globalLogger.WithOptions(zap.AddCallerSkip(1))
-	store, ok := loggerRuntimesStore.Load(goruntime.Goid())
-	if !ok || store == nil {
-		logSkipCaller.Panic(message, fields...)
-		return
-	}
-
-	withValue := store.(RegisterRuntime)
-	zapRequestID := zap.String(withValue.Key, withValue.Value)
-	fields = append(fields, zapRequestID)
-	logSkipCaller.Panic(message, fields...)
+	GetLogger().WithOptions(zap.AddCallerSkip(1)).Panic(message, withRuntimeValue(fields)...)
 }
@@ -2,22 +2,59 @@ package routes
 
 import (
 	"net/http"
-	"regexp"
 	"strings"
 	"sync"
 
+	"github.com/he-end/simproute/goruntime"
 	logger "github.com/he-end/simproute/route_logger"
-	"github.com/he-end/simproute/routes/response"
+	"github.com/he-end/simproute/tracing"
 )
 
 type HandlerFunc http.HandlerFunc
 
+type partKind uint8
+
+const (
+	partStatic partKind = iota
+	partParam
+	// catch-all, only allowed at the end of a pattern
+	partCatchAll
+)
+
+// patternPart is either static text or a parameter name of a route pattern
+type patternPart struct {
+	kind partKind
+	text string
+	// only for {param:constraint}, nil means any value
+	constraint *constraint
+}
+
 type routePattern struct {
 	pattern    string
-	regex      *regexp.Regexp
+	parts      []patternPart
 	paramNames []string
 }
 
+// route is one call to Handle, kept so groups can re-wrap it with their
+// middleware when merging into the parent
+type route struct {
+	methods []string
+	// full path, prefix included
+	pattern string
+	handler HandlerFunc
+	// per route middleware, first is the outer-most
+	mws []func(http.Handler) http.Handler
+	// middleware of the enclosing groups, also wraps the automatic
+	// OPTIONS answer of the path (e.g. a group CORS policy)
+	scopeMws []func(http.Handler) http.Handler
+	// set with Route.Name, used by Router.URL
+	name string
+	// documentation, set with the Route methods
+	op Operation
+	// root Router the route is stored on, nil until registered there
+	owner *Router
+}
+
 type Router struct {
 	MU sync.RWMutex
 
@@ -31,24 +68,85 @@ type Router struct {
 	// prefix for gouping
 	Prefix string
 
+	// radix tree of DynamicRoutes used for lookup
+	tree *node
+
+	// automatic OPTIONS handler per static path or dynamic pattern
+	options map[string]http.Handler
+
+	// registered routes in order
+	routes []*route
+
+	// named routes for URL
+	names map[string]*route
+
+	// registered shapes per method, used to detect conflicts
+	claims map[string][]claim
+
+	// set by With, routes are registered directly on the parent
+	parent *Router
+	// set by Group, routes are only collected until the group is merged
+	grouped bool
+	// root Router of a Group, conflicts are checked there
+	root *Router
+
+	// on the root Router it wraps every request, on a Group or With router
+	// it wraps only the routes registered there
 	Mws []func(http.Handler) http.Handler
 
 	AutoCorelation bool
 
+	// where the correlation id comes from and where it is sent back,
+	// used when AutoCorelation is true
+	Corelation CorelationConfig
+
 	RecoverOnPanic bool
+
+	// runtime mode of the requests served, New sets the process mode
+	// (SIMPROUTE_MODE) when no WithMode is given. response.For(r) follows it
+	Mode goruntime.Mode
+
+	// in ModeDev the 500 answering a recovered panic shows the panic value,
+	// its error chain and the stack trace. ModeProd never shows them
+	DebugErrors bool
+
+	// log one "http_request" line per request
+	AccessLog bool
+
+	// records a server span per request when set, nil disables tracing
+	Tracer *tracing.Tracer
+
+	// NotFound answers requests without a matching route,
+	// nil uses the JSON 404 of the response package
+	NotFound http.Handler
+
+	// MethodNotAllowed answers a path registered under other methods, the
+	// Allow header is already set. nil uses the JSON 405 of the response package
+	MethodNotAllowed http.Handler
+
+	// PanicHandler writes the response after a panic recovered by
+	// RecoverOnPanic, nil uses the JSON 500 of the response package
+	PanicHandler func(w http.ResponseWriter, r *http.Request, recovered any, stack []byte)
+
+	// what Handle does when a route conflicts with an earlier one,
+	// TryHandle returns the conflict instead
+	Conflicts ConflictPolicy
+
+	// request pipeline, built once on the first request or by Freeze
+	handler http.Handler
+	frozen  bool
 }
 
 // # return of
 //
 //	Autocorelation = default(true)
 //	RecoverOnPanic = default(true)
-func New() *Router {
-	logger.InitLogger("dev", "debug")
-	if resp := response.NewWithGlobalLogger(); resp != nil {
-		resp.Dev = true
-	}
-
-	return &Router{
+//	AccessLog      = default(true)
+//
+// opts are applied in order on the new Router, then the global logger is
+// initialized for the process mode (SIMPROUTE_MODE or goruntime.SetMode)
+func New(opts ...Option) *Router {
+	r := &Router{
 		Routes: make(map[string]map[string]http.Handler),
 		DynamicRoutes: make([]struct {
 			pattern routePattern
@@ -57,60 +155,144 @@ func New() *Router {
 
 		Mws:            make([]func(http.Handler) http.Handler, 0, 4),
 		AutoCorelation: true,
+		Corelation:     DefaultCorelationConfig(),
 		RecoverOnPanic: true,
+		AccessLog:      true,
 	}
-
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.Mode == "" {
+		r.Mode = goruntime.CurrentMode()
+	}
+	logger.InitFromMode()
+	return r
 }
 
-// compilePattern converts a route pattern to a regex and extracts parameter names
-// Supports both :param and {param} syntax
-// Example: "/users/:id/posts/{postId}" -> regex with paramNames ["id", "postId"]
+// compilePattern splits a route pattern into static text and parameters
+// Supports both :param and {param} syntax, and *param or {param...} for a
+// catch-all that captures the rest of the path (must be the last part)
+// Example: "/users/:id/posts/{postId}" -> parts with paramNames ["id", "postId"]
+// Example: "/static/*filepath" -> "/static/css/app.css" gives filepath "css/app.css"
+//
+// A brace param can be constrained with a type (int, uint, uuid, alpha, date
+// or one added with RegisterConstraint) or a regex matched against the value
+// Example: "/users/{id:int}/posts/{slug:[a-z-]+}"
 func compilePattern(pattern string) routePattern {
-	regexPattern := pattern
+	parts := make([]patternPart, 0, 4)
 	paramNames := make([]string, 0)
 
-	syntaxParam := `([^/]+)`
-	// colon param handle :param
-	colonRegex := regexp.MustCompile(`:([a-zA-Z_][a-zA-Z0-9]*)`)
-	colonMatch := colonRegex.FindAllStringSubmatch(pattern, -1)
-	for _, match := range colonMatch {
-		param := match[1]
-		// if param != "" {
-		// 	paramNames = append(paramNames, param)
-		// }
-		paramNames = append(paramNames, param)
-		regexPattern = strings.ReplaceAll(regexPattern, match[0], syntaxParam)
-	}
-
-	// brace param handle {param}
-	braceRegex := regexp.MustCompile(`\{([a-zA-Z][a-zA-Z0-9]*)\}`)
-	barceMatch := braceRegex.FindAllStringSubmatch(pattern, -1)
-	for _, match := range barceMatch {
-		param := match[1]
-		// if param != "" {
-		// 		paramNames = append(paramNames, param)
-		// }
-		paramNames = append(paramNames, param)
-		regexPattern = strings.ReplaceAll(regexPattern, match[0], syntaxParam)
-	}
-
-	regexPattern = regexp.QuoteMeta(regexPattern)
-	regexPattern = strings.ReplaceAll(regexPattern, `\(\[\^/\]\+\)`, syntaxParam)
-	regexPattern = "^" + regexPattern + "$"
-
-	compileRegex := regexp.MustCompile(regexPattern)
+	static := 0
+	addStatic := func(end int) {
+		if end > static {
+			parts = append(parts, patternPart{kind: partStatic, text: pattern[static:end]})
+		}
+	}
+	for i := 0; i < len(pattern); {
+		name, next, kind := "", 0, partParam
+		var cons *constraint
+		switch pattern[i] {
+		// colon param handle :param, star handle *param
+		case ':', '*':
+			j := i + 1
+			for j < len(pattern) && isParamChar(pattern[j], j == i+1) {
+				j++
+			}
+			if j > i+1 {
+				name, next = pattern[i+1:j], j
+				if pattern[i] == '*' {
+					kind = partCatchAll
+				}
+			}
+		// brace param handle {param} and {param...}
+		case '{':
+			j := i + 1
+			for j < len(pattern) && isParamChar(pattern[j], j == i+1) {
+				j++
+			}
+			if j > i+1 && strings.HasPrefix(pattern[j:], "...}") {
+				name, next, kind = pattern[i+1:j], j+4, partCatchAll
+			} else if j > i+1 && j < len(pattern) && pattern[j] == '}' {
+				name, next = pattern[i+1:j], j+1
+			} else if j > i+1 && j < len(pattern) && pattern[j] == ':' {
+				// constraint ends on the matching brace, regex may use {n}
+				depth := 1
+				for k := j + 1; k < len(pattern); k++ {
+					if pattern[k] == '{' {
+						depth++
+					} else if pattern[k] == '}' {
+						depth--
+					}
+					if depth == 0 {
+						name, next = pattern[i+1:j], k+1
+						cons = compileConstraint(pattern[j+1 : k])
+						break
+					}
+				}
+			}
+		}
+		if name == "" {
+			i++
+			continue
+		}
+		if kind == partCatchAll && next != len(pattern) {
+			panic("routes: catch-all parameter " + name + " must be at the end of pattern " + pattern)
+		}
+		addStatic(i)
+		parts = append(parts, patternPart{kind: kind, text: name, constraint: cons})
+		paramNames = append(paramNames, name)
+		i, static = next, next
+	}
+	addStatic(len(pattern))
 
 	return routePattern{
 		pattern:    pattern,
-		regex:      compileRegex,
+		parts:      parts,
 		paramNames: paramNames,
 	}
 }
 
+func isParamChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	}
+	return false
+}
+
 func isDynamixRoute(pattern string) bool {
-	return strings.Contains(pattern, ":") || strings.Contains(pattern, "{")
+	return strings.ContainsAny(pattern, ":{*")
 }
-func (r *Router) Handle(method []string, path string, handler HandlerFunc) {
+
+// Handle registers handler for the methods and path, the optional mws wrap
+// only this route (first is the outer-most)
+//
+// A route conflicting with an earlier one is reported by the Conflicts policy.
+// The returned Route can be named for Router.URL
+func (r *Router) Handle(method []string, path string, handler HandlerFunc, mws ...func(http.Handler) http.Handler) *Route {
+	rt := r.newRoute(method, path, handler, mws)
+	top := r.top()
+	if err := top.claim(rt, true); err != nil {
+		top.reportConflict(err)
+	}
+	r.register(rt)
+	return &Route{rt: rt}
+}
+
+// TryHandle is Handle returning a *ConflictError instead of applying the
+// Conflicts policy, the route is not registered on error
+func (r *Router) TryHandle(method []string, path string, handler HandlerFunc, mws ...func(http.Handler) http.Handler) (*Route, error) {
+	rt := r.newRoute(method, path, handler, mws)
+	if err := r.top().claim(rt, false); err != nil {
+		return nil, err
+	}
+	r.register(rt)
+	return &Route{rt: rt}, nil
+}
+
+func (r *Router) newRoute(method []string, path string, handler HandlerFunc, mws []func(http.Handler) http.Handler) *route {
 	// fixing path if abnormal
 	if path == "" || path[0] != '/' {
 		path = "/" + path
@@ -124,75 +306,138 @@ func (r *Router) Handle(method []string, path string, handler HandlerFunc) {
 			path = r.Prefix + path
 		}
 	}
+
+	methods := make([]string, 0, len(method))
+	for _, m := range method {
+		method := strings.ToUpper(strings.TrimSpace(m))
+		if method == "" {
+			continue
+		}
+		methods = append(methods, method)
+	}
+
+	return &route{
+		methods: methods,
+		pattern: path,
+		handler: handler,
+		mws:     append([]func(http.Handler) http.Handler(nil), mws...),
+	}
+}
+
+// top returns the Router serving the requests, for Group and With routers
+// the one they were created from
+func (r *Router) top() *Router {
+	for r.parent != nil {
+		r = r.parent
+	}
+	if r.root != nil {
+		return r.root
+	}
+	return r
+}
+
+// register stores rt wrapped with its middleware
+func (r *Router) register(rt *route) {
+	// With router, add its middleware and register on the parent
+	if r.parent != nil {
+		r.MU.RLock()
+		rt.mws = append(append([]func(http.Handler) http.Handler(nil), r.Mws...), rt.mws...)
+		r.MU.RUnlock()
+		r.parent.register(rt)
+		return
+	}
+	// Group router, collect until the group is merged
+	if r.grouped {
+		r.MU.Lock()
+		r.routes = append(r.routes, rt)
+		r.MU.Unlock()
+		return
+	}
+
+	var handler http.Handler = http.HandlerFunc(rt.handler)
+	for i := len(rt.mws) - 1; i >= 0; i-- {
+		handler = rt.mws[i](handler)
+	}
+
+	var pattern routePattern
+	dynamic := isDynamixRoute(rt.pattern)
+	if dynamic {
+		pattern = compilePattern(rt.pattern)
+	}
+
 	// set MU
 	r.MU.Lock()
 	defer r.MU.Unlock()
 
-	if isDynamixRoute(path) {
-		// check if exists pattern
-		pattern := compilePattern(path)
-		// pattern check
-		for i, dnr := range r.DynamicRoutes {
-			if dnr.pattern.pattern == pattern.pattern {
-				for _, m := range method {
-					method := strings.ToUpper(strings.TrimSpace(m))
-					if method == "" {
-						continue
-					}
-					r.DynamicRoutes[i].method[method] = http.HandlerFunc(handler)
-				}
-				return
-			}
+	r.routes = append(r.routes, rt)
+	rt.owner = r
+	if rt.name != "" {
+		r.addName(rt)
+	}
+	if _, ok := r.options[rt.pattern]; !ok {
+		if r.options == nil {
+			r.options = make(map[string]http.Handler)
 		}
-
-		// if code here, thats mean pattern not yet to register
-		// add pattern
-		methodMaps := make(map[string]http.Handler)
-		for _, m := range method {
-			method := strings.ToUpper(strings.TrimSpace(m))
-			if method == "" {
-				continue
-			}
-			methodMaps[method] = http.HandlerFunc(handler)
+		var options http.Handler = http.HandlerFunc(r.handleOptions)
+		for i := len(rt.scopeMws) - 1; i >= 0; i-- {
+			options = rt.scopeMws[i](options)
+		}
+		r.options[rt.pattern] = options
+	}
+	if dynamic {
+		for _, method := range rt.methods {
+			r.addDynamicRoute(pattern, method, handler)
 		}
-
-		r.DynamicRoutes = append(r.DynamicRoutes, struct {
-			pattern routePattern
-			method  map[string]http.Handler
-		}{
-			pattern: pattern,
-			method:  methodMaps,
-		})
-
 	} else {
-		if r.Routes[path] == nil {
-			r.Routes[path] = map[string]http.Handler{}
+		if r.Routes[rt.pattern] == nil {
+			r.Routes[rt.pattern] = map[string]http.Handler{}
 		}
-		for _, m := range method {
-			method := strings.ToUpper(strings.TrimSpace(m))
-			if method == "" {
-				continue
-			}
-			r.Routes[path][method] = http.HandlerFunc(handler)
+		for _, method := range rt.methods {
+			r.Routes[rt.pattern][method] = handler
 		}
+	}
+}
 
+// addDynamicRoute stores the handler for pattern and method in DynamicRoutes
+// and in the lookup tree, r.MU must be held
+func (r *Router) addDynamicRoute(pattern routePattern, method string, handler http.Handler) {
+	// check if exists pattern
+	for _, dnr := range r.DynamicRoutes {
+		if dnr.pattern.pattern == pattern.pattern {
+			dnr.method[method] = handler
+			return
+		}
 	}
 
+	// if code here, thats mean pattern not yet to register
+	methodMaps := map[string]http.Handler{method: handler}
+	r.DynamicRoutes = append(r.DynamicRoutes, struct {
+		pattern routePattern
+		method  map[string]http.Handler
+	}{
+		pattern: pattern,
+		method:  methodMaps,
+	})
+
+	if r.tree == nil {
+		r.tree = &node{}
+	}
+	r.tree.insert(pattern.parts, &leaf{pattern: pattern, methods: methodMaps})
 }
 
-func (r *Router) Get(path string, handler HandlerFunc) {
-	r.Handle([]string{"GET"}, path, handler)
+func (r *Router) Get(path string, handler HandlerFunc, mws ...func(http.Handler) http.Handler) *Route {
+	return r.Handle([]string{"GET"}, path, handler, mws...)
 }
 
-func (r *Router) POST(path string, handler HandlerFunc) {
-	r.Handle([]string{"POST"}, path, handler)
+func (r *Router) POST(path string, handler HandlerFunc, mws ...func(http.Handler) http.Handler) *Route {
+	return r.Handle([]string{"POST"}, path, handler, mws...)
 }
-func (r *Router) PATCH(path string, handler HandlerFunc) {
-	r.Handle([]string{"PATCH"}, path, handler)
+func (r *Router) PATCH(path string, handler HandlerFunc, mws ...func(http.Handler) http.Handler) *Route {
+	return r.Handle([]string{"PATCH"}, path, handler, mws...)
 }
-func (r *Router) PUT(path string, handler HandlerFunc) {
-	r.Handle([]string{"PUT"}, path, handler)
+func (r *Router) PUT(path string, handler HandlerFunc, mws ...func(http.Handler) http.Handler) *Route {
+	return r.Handle([]string{"PUT"}, path, handler, mws...)
 }
-func (r *Router) DELETE(path string, handler HandlerFunc) {
-	r.Handle([]string{"DELETE"}, path, handler)
+func (r *Router) DELETE(path string, handler HandlerFunc, mws ...func(http.Handler) http.Handler) *Route {
+	return r.Handle([]string{"DELETE"}, path, handler, mws...)
 }
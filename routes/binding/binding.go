@@ -0,0 +1,248 @@
+// Package binding fills a struct from the request body, path parameters,
+// query values and headers following its struct tags
+//
+//	type CreatePost struct {
+//		UserID int64    `path:"id"`
+//		Draft  bool     `query:"draft" default:"false"`
+//		Tenant string   `header:"X-Tenant"`
+//		Title  string   `json:"title" form:"title"`
+//		Tags   []string `json:"tags" form:"tag"`
+//	}
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/he-end/simproute/routes/response"
+	"github.com/he-end/simproute/routes/routeutil"
+)
+
+// MaxMultipartMemory is the memory used by a multipart form before files
+// are stored on disk
+var MaxMultipartMemory int64 = 32 << 20
+
+// Error is returned by Bind when the request does not fit the struct
+type Error struct {
+	// response error code, response.ErrCodeInvalidJSON for a malformed
+	// body, response.ErrCodeTypeUnsupported for an unsupported Content-Type,
+	// response.ErrCodeValidationError for a value that cannot be converted
+	Code string
+	// http status of the answer, 400 or 415
+	Status int
+	// "json", "form", "path", "query" or "header"
+	Source string
+	// key of the value, e.g. the query name or the json field path
+	Field string
+	Value string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	if e.Field == "" {
+		return e.Source + ": " + e.Err.Error()
+	}
+	if e.Value == "" {
+		return fmt.Sprintf("%s %q: %s", e.Source, e.Field, e.Err)
+	}
+	return fmt.Sprintf("%s %q: invalid value %q: %s", e.Source, e.Field, e.Value, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Bind fills dst, a pointer to a struct, from r. In order:
+//
+//  1. `default:"..."` values
+//  2. the body, JSON into the `json` fields or a form into the `form` fields,
+//     chosen by the Content-Type (JSON when it is not set)
+//  3. `path:"name"` route parameters
+//  4. `query:"name"` query values
+//  5. `header:"Name"` headers
+//
+// A value missing from the request keeps what the step before set.
+// Slices take every value of a query, form key or header.
+func Bind(r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: dst must be a non nil pointer to a struct, got %T", dst)
+	}
+	v = v.Elem()
+	fields := fieldsOf(v.Type())
+
+	for _, f := range fields {
+		if f.def == "" {
+			continue
+		}
+		if err := setValue(v.FieldByIndex(f.index), []string{f.def}); err != nil {
+			return fmt.Errorf("binding: invalid default of %s.%s: %w", v.Type().Name(), f.name, err)
+		}
+	}
+
+	if err := bindBody(r, dst, v, fields); err != nil {
+		return err
+	}
+
+	params := routeutil.GetRouteParams(r.Context())
+	query := r.URL.Query()
+	for _, f := range fields {
+		if f.path != "" {
+			if value, ok := params[f.path]; ok {
+				if err := set(v, f, "path", f.path, []string{value}); err != nil {
+					return err
+				}
+			}
+		}
+		if f.query != "" {
+			if values, ok := query[f.query]; ok {
+				if err := set(v, f, "query", f.query, values); err != nil {
+					return err
+				}
+			}
+		}
+		if f.header != "" {
+			if values := r.Header.Values(f.header); len(values) > 0 {
+				if err := set(v, f, "header", f.header, values); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func bindBody(r *http.Request, dst any, v reflect.Value, fields []field) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil
+	}
+
+	mediaType := "application/json"
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return &Error{Code: response.ErrCodeTypeUnsupported, Status: http.StatusUnsupportedMediaType, Source: "body", Err: err}
+		}
+		mediaType = mt
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return decodeJSON(r.Body, dst)
+	case mediaType == "application/x-www-form-urlencoded", mediaType == "multipart/form-data":
+		var err error
+		if mediaType == "multipart/form-data" {
+			err = r.ParseMultipartForm(MaxMultipartMemory)
+		} else {
+			err = r.ParseForm()
+		}
+		if err != nil {
+			return &Error{Code: response.ErrCodeInvalidRequest, Status: http.StatusBadRequest, Source: "form", Err: err}
+		}
+		for _, f := range fields {
+			if f.form == "" {
+				continue
+			}
+			if values, ok := r.PostForm[f.form]; ok {
+				if err := set(v, f, "form", f.form, values); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return &Error{
+		Code:   response.ErrCodeTypeUnsupported,
+		Status: http.StatusUnsupportedMediaType,
+		Source: "body",
+		Err:    fmt.Errorf("unsupported Content-Type %q", mediaType),
+	}
+}
+
+func decodeJSON(body io.Reader, dst any) error {
+	err := json.NewDecoder(body).Decode(dst)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &Error{
+			Code:   response.ErrCodeValidationError,
+			Status: http.StatusBadRequest,
+			Source: "json",
+			Field:  typeErr.Field,
+			Err:    fmt.Errorf("expected %s, got %s", typeErr.Type, typeErr.Value),
+		}
+	}
+	return &Error{Code: response.ErrCodeInvalidJSON, Status: http.StatusBadRequest, Source: "json", Err: err}
+}
+
+func set(v reflect.Value, f field, source, key string, values []string) error {
+	if err := setValue(v.FieldByIndex(f.index), values); err != nil {
+		return &Error{
+			Code:   response.ErrCodeValidationError,
+			Status: http.StatusBadRequest,
+			Source: source,
+			Field:  key,
+			Value:  strings.Join(values, ","),
+			Err:    err,
+		}
+	}
+	return nil
+}
+
+// field is a struct field with at least one binding tag
+type field struct {
+	index []int
+	name  string
+
+	path, query, header, form string
+	def                       string
+}
+
+var fieldsCache sync.Map // reflect.Type -> []field
+
+func fieldsOf(t reflect.Type) []field {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.([]field)
+	}
+	fields := collectFields(t, nil)
+	fieldsCache.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		idx := append(append([]int(nil), index...), i)
+
+		// embedded struct, its tagged fields are promoted
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectFields(sf.Type, idx)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		f := field{
+			index:  idx,
+			name:   sf.Name,
+			path:   sf.Tag.Get("path"),
+			query:  sf.Tag.Get("query"),
+			header: sf.Tag.Get("header"),
+			form:   sf.Tag.Get("form"),
+			def:    sf.Tag.Get("default"),
+		}
+		if f.path != "" || f.query != "" || f.header != "" || f.form != "" || f.def != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
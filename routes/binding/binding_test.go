@@ -0,0 +1,217 @@
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/he-end/simproute/routes/response"
+	"github.com/he-end/simproute/routes/routeutil"
+)
+
+type Paging struct {
+	Page  int `query:"page" default:"1"`
+	Limit int `query:"limit" default:"20"`
+}
+
+type createPost struct {
+	Paging
+	UserID  int64         `path:"id"`
+	Draft   bool          `query:"draft" default:"false"`
+	Tenant  string        `header:"X-Tenant"`
+	Title   string        `json:"title" form:"title"`
+	Tags    []string      `json:"tags" form:"tag"`
+	IDs     []uint        `query:"ids"`
+	Langs   []string      `header:"Accept-Language"`
+	Score   *float64      `query:"score"`
+	Since   time.Time     `query:"since"`
+	Timeout time.Duration `query:"timeout" default:"5s"`
+	Note    *string       `json:"note" form:"note"`
+}
+
+func newRequest(method, target, contentType, body string, params routeutil.RouteParams) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	if params != nil {
+		r = r.WithContext(routeutil.SetRouteParams(r.Context(), params))
+	}
+	return r
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestBind(t *testing.T) {
+	since := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	defaults := createPost{Paging: Paging{Page: 1, Limit: 20}, Timeout: 5 * time.Second}
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		header http.Header
+		want   func(p *createPost)
+	}{
+		{
+			name: "defaults only",
+			req:  newRequest(http.MethodGet, "/", "", "", nil),
+			want: func(p *createPost) {},
+		},
+		{
+			name: "json without content type",
+			req:  newRequest(http.MethodPost, "/", "", `{"title":"hi","tags":["a","b"]}`, nil),
+			want: func(p *createPost) { p.Title, p.Tags = "hi", []string{"a", "b"} },
+		},
+		{
+			name: "json suffix with charset",
+			req:  newRequest(http.MethodPost, "/", "application/vnd.api+json; charset=utf-8", `{"title":"hi","note":"n"}`, nil),
+			want: func(p *createPost) { p.Title, p.Note = "hi", ptr("n") },
+		},
+		{
+			name: "urlencoded form",
+			req:  newRequest(http.MethodPost, "/", "application/x-www-form-urlencoded", "title=hi&tag=a&tag=b&note=n", nil),
+			want: func(p *createPost) { p.Title, p.Tags, p.Note = "hi", []string{"a", "b"}, ptr("n") },
+		},
+		{
+			name: "path query and header",
+			req:  newRequest(http.MethodGet, "/?page=3&draft=true&ids=1&ids=2&score=0.5&since=2026-01-02&timeout=1m", "", "", routeutil.RouteParams{"id": "42"}),
+			header: http.Header{
+				"X-Tenant":        {"acme"},
+				"Accept-Language": {"en", "fr"},
+			},
+			want: func(p *createPost) {
+				p.UserID, p.Page, p.Draft = 42, 3, true
+				p.IDs, p.Score, p.Since, p.Timeout = []uint{1, 2}, ptr(0.5), since, time.Minute
+				p.Tenant, p.Langs = "acme", []string{"en", "fr"}
+			},
+		},
+		{
+			name: "query overrides the body",
+			req:  newRequest(http.MethodPost, "/?page=2", "application/json", `{"Page":9,"Limit":5}`, nil),
+			want: func(p *createPost) { p.Page, p.Limit = 2, 5 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.header {
+				tt.req.Header[k] = v
+			}
+			var got createPost
+			if err := Bind(tt.req, &got); err != nil {
+				t.Fatalf("Bind = %v", err)
+			}
+			want := defaults
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Bind =\n%+v\nwant\n%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestBindMultipart(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("title", "hi")
+	w.WriteField("tag", "a")
+	w.WriteField("tag", "b")
+	w.Close()
+
+	var got createPost
+	if err := Bind(newRequest(http.MethodPost, "/", w.FormDataContentType(), body.String(), nil), &got); err != nil {
+		t.Fatalf("Bind = %v", err)
+	}
+	if got.Title != "hi" || !reflect.DeepEqual(got.Tags, []string{"a", "b"}) {
+		t.Errorf("Bind = %+v", got)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *http.Request
+		code   string
+		status int
+		source string
+		field  string
+	}{
+		{"unsupported content type", newRequest(http.MethodPost, "/", "text/plain", "hi", nil),
+			response.ErrCodeTypeUnsupported, http.StatusUnsupportedMediaType, "body", ""},
+		{"malformed content type", newRequest(http.MethodPost, "/", "application/json; =", "{}", nil),
+			response.ErrCodeTypeUnsupported, http.StatusUnsupportedMediaType, "body", ""},
+		{"malformed json", newRequest(http.MethodPost, "/", "application/json", `{"title":`, nil),
+			response.ErrCodeInvalidJSON, http.StatusBadRequest, "json", ""},
+		{"json type", newRequest(http.MethodPost, "/", "application/json", `{"title":1}`, nil),
+			response.ErrCodeValidationError, http.StatusBadRequest, "json", "title"},
+		{"path", newRequest(http.MethodGet, "/", "", "", routeutil.RouteParams{"id": "x"}),
+			response.ErrCodeValidationError, http.StatusBadRequest, "path", "id"},
+		{"query", newRequest(http.MethodGet, "/?page=two", "", "", nil),
+			response.ErrCodeValidationError, http.StatusBadRequest, "query", "page"},
+		{"query slice item", newRequest(http.MethodGet, "/?ids=1&ids=-1", "", "", nil),
+			response.ErrCodeValidationError, http.StatusBadRequest, "query", "ids"},
+		{"query time", newRequest(http.MethodGet, "/?since=yesterday", "", "", nil),
+			response.ErrCodeValidationError, http.StatusBadRequest, "query", "since"},
+		{"query after form", newRequest(http.MethodPost, "/?draft=maybe", "application/x-www-form-urlencoded", "title=hi", nil),
+			response.ErrCodeValidationError, http.StatusBadRequest, "query", "draft"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst createPost
+			err := Bind(tt.req, &dst)
+			var be *Error
+			if !errors.As(err, &be) {
+				t.Fatalf("Bind = %v, want *Error", err)
+			}
+			if be.Code != tt.code || be.Status != tt.status || be.Source != tt.source || be.Field != tt.field {
+				t.Errorf("Bind = %+v, want %s %d %s %q", be, tt.code, tt.status, tt.source, tt.field)
+			}
+		})
+	}
+}
+
+func TestBindFormAndHeaderErrors(t *testing.T) {
+	type req struct {
+		Count   int `form:"count"`
+		Retries int `header:"X-Retries"`
+	}
+	form := newRequest(http.MethodPost, "/", "application/x-www-form-urlencoded", "count=lots", nil)
+	header := newRequest(http.MethodGet, "/", "", "", nil)
+	header.Header.Set("X-Retries", "many")
+
+	for _, tt := range []struct {
+		req                  *http.Request
+		source, field, value string
+	}{
+		{form, "form", "count", "lots"},
+		{header, "header", "X-Retries", "many"},
+	} {
+		var be *Error
+		err := Bind(tt.req, &req{})
+		if !errors.As(err, &be) || be.Status != http.StatusBadRequest || be.Source != tt.source || be.Field != tt.field || be.Value != tt.value {
+			t.Errorf("Bind = %v, want a %s error on %s", err, tt.source, tt.field)
+		}
+	}
+}
+
+func TestBindInvalidDst(t *testing.T) {
+	r := newRequest(http.MethodGet, "/", "", "", nil)
+	for _, dst := range []any{createPost{}, (*createPost)(nil), new(int)} {
+		var be *Error
+		if err := Bind(r, dst); err == nil || errors.As(err, &be) {
+			t.Errorf("Bind(%T) = %v, want a plain error", dst, err)
+		}
+	}
+
+	type badDefault struct {
+		N int `query:"n" default:"x"`
+	}
+	if err := Bind(r, &badDefault{}); err == nil || !strings.Contains(err.Error(), "invalid default") {
+		t.Errorf("Bind = %v, want an invalid default error", err)
+	}
+}
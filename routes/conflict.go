@@ -0,0 +1,173 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	logger "github.com/he-end/simproute/route_logger"
+	"go.uber.org/zap"
+)
+
+// ConflictPolicy tells Handle what to do with a route conflict
+type ConflictPolicy uint8
+
+const (
+	// log a warning and register the route anyway (default)
+	ConflictWarn ConflictPolicy = iota
+	// panic, so the mistake fails at startup or in CI
+	ConflictPanic
+	// register the route without reporting
+	ConflictIgnore
+)
+
+// ConflictKind is the reason a route conflicts with an earlier one
+type ConflictKind uint8
+
+const (
+	// same method and the same pattern, the new handler replaces the old one
+	ConflictDuplicate ConflictKind = iota + 1
+	// same method and a pattern of the same shape written differently
+	// (e.g. /users/:id and /users/{name}), the new route is unreachable
+	ConflictShadowed
+	// same method and the same shape with different constraints on the same
+	// parameters (e.g. {id:int} and {id:uint}), a value matching both goes
+	// to the route registered first
+	ConflictAmbiguous
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case ConflictDuplicate:
+		return "duplicate"
+	case ConflictShadowed:
+		return "shadowed"
+	case ConflictAmbiguous:
+		return "ambiguous"
+	}
+	return "unknown"
+}
+
+// ErrRouteConflict is wrapped by every ConflictError
+var ErrRouteConflict = errors.New("routes: route conflict")
+
+// ConflictError describes a route conflicting with an earlier registered one
+type ConflictError struct {
+	Kind    ConflictKind
+	Method  string
+	Pattern string
+	// method and pattern of the earlier route
+	ExistingMethod string
+	Existing       string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("routes: %s route %s %s, conflicts with %s %s", e.Kind, e.Method, e.Pattern, e.ExistingMethod, e.Existing)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrRouteConflict
+}
+
+// claim is one method of a registered pattern, indexed by its shape
+type claim struct {
+	method  string
+	pattern string
+	// constraint expr per parameter, "" when unconstrained
+	constraints []string
+}
+
+// shapeOf returns the pattern with parameter names and constraints removed
+// and the constraint of each parameter
+func shapeOf(pattern string) (string, []string) {
+	var b strings.Builder
+	var cons []string
+	compiled := compilePattern(pattern)
+	for _, part := range compiled.parts {
+		switch part.kind {
+		case partStatic:
+			b.WriteString(part.text)
+			continue
+		case partParam:
+			b.WriteString("\x00:")
+		case partCatchAll:
+			b.WriteString("\x00*")
+		}
+		if part.constraint != nil {
+			cons = append(cons, part.constraint.expr)
+		} else {
+			cons = append(cons, "")
+		}
+	}
+	return b.String(), cons
+}
+
+// claim checks rt against the routes registered before it and records it.
+// A conflicting rt is only recorded when keep is set, Handle registers it
+// anyway and later routes must be checked against it.
+func (r *Router) claim(rt *route, keep bool) error {
+	shape, cons := shapeOf(rt.pattern)
+
+	r.MU.Lock()
+	defer r.MU.Unlock()
+	if r.claims == nil {
+		r.claims = make(map[string][]claim)
+	}
+	var conflict *ConflictError
+	for _, method := range rt.methods {
+		for _, c := range r.claims[shape] {
+			if c.method != method {
+				continue
+			}
+			kind := conflictOf(c, rt.pattern, cons)
+			// a duplicate is reported before any other kind
+			if kind != 0 && (conflict == nil || kind == ConflictDuplicate && conflict.Kind != ConflictDuplicate) {
+				conflict = &ConflictError{Kind: kind, Method: method, Pattern: rt.pattern, ExistingMethod: c.method, Existing: c.pattern}
+			}
+		}
+	}
+	if conflict != nil && !keep {
+		return conflict
+	}
+	for _, method := range rt.methods {
+		r.claims[shape] = append(r.claims[shape], claim{method: method, pattern: rt.pattern, constraints: cons})
+	}
+	if conflict == nil {
+		return nil
+	}
+	return conflict
+}
+
+// conflictOf compares a new pattern with an earlier claim of the same shape,
+// 0 means both routes are reachable
+func conflictOf(c claim, pattern string, cons []string) ConflictKind {
+	if c.pattern == pattern {
+		return ConflictDuplicate
+	}
+	same := true
+	for i := range cons {
+		if cons[i] == c.constraints[i] {
+			continue
+		}
+		same = false
+		// a constrained parameter is tried before an unconstrained one,
+		// so the more specific route still wins
+		if cons[i] == "" || c.constraints[i] == "" {
+			return 0
+		}
+	}
+	if same {
+		return ConflictShadowed
+	}
+	return ConflictAmbiguous
+}
+
+// reportConflict applies the Conflicts policy of r to err
+func (r *Router) reportConflict(err error) {
+	switch r.Conflicts {
+	case ConflictPanic:
+		panic(err)
+	case ConflictWarn:
+		logger.GetLogger().Warn("route conflict", zap.Error(err))
+	}
+}
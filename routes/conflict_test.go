@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var noop = func(w http.ResponseWriter, req *http.Request) {}
+
+func TestConflictKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  string // "METHOD pattern"
+		second string
+		kind   ConflictKind
+	}{
+		{"duplicate", "GET /users/:id", "GET /users/:id", ConflictDuplicate},
+		{"shadowed", "GET /users/:id", "GET /users/{name}", ConflictShadowed},
+		{"shadowed catch-all", "GET /static/*path", "GET /static/{rest...}", ConflictShadowed},
+		{"ambiguous", "GET /users/{id:int}", "GET /users/{id:uint}", ConflictAmbiguous},
+		{"static duplicate", "GET /about", "GET /about", ConflictDuplicate},
+		// reachable routes
+		{"other method same names", "GET /users/:id", "DELETE /users/{id}", 0},
+		{"other method other names", "GET /u/:id", "POST /u/{name}", 0},
+		{"other method constrained", "GET /u/{id:int}", "DELETE /u/{userID:int}", 0},
+		{"constrained and unconstrained", "GET /users/{id:int}", "GET /users/{name}", 0},
+		{"other shape", "GET /users/:id", "GET /users/:id/posts", 0},
+		{"static and param", "GET /users/me", "GET /users/:id", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			method, pattern := split(tt.first)
+			if _, err := r.TryHandle([]string{method}, pattern, noop); err != nil {
+				t.Fatalf("first route: %v", err)
+			}
+			method, pattern = split(tt.second)
+			_, err := r.TryHandle([]string{method}, pattern, noop)
+			if tt.kind == 0 {
+				if err != nil {
+					t.Fatalf("unexpected conflict: %v", err)
+				}
+				return
+			}
+			var ce *ConflictError
+			if !errors.As(err, &ce) || !errors.Is(err, ErrRouteConflict) {
+				t.Fatalf("error = %v, want a %s ConflictError", err, tt.kind)
+			}
+			if ce.Kind != tt.kind || ce.Pattern != pattern || ce.Method != method {
+				t.Errorf("conflict = %+v, want kind %s for %s", ce, tt.kind, tt.second)
+			}
+		})
+	}
+}
+
+func split(route string) (string, string) {
+	for i := 0; i < len(route); i++ {
+		if route[i] == ' ' {
+			return route[:i], route[i+1:]
+		}
+	}
+	return "", route
+}
+
+func TestConflictPolicies(t *testing.T) {
+	serve := func(r *Router, method, path string) int {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec.Code
+	}
+
+	t.Run("warn registers", func(t *testing.T) {
+		r := New()
+		r.AccessLog = false
+		r.Get("/u/:id", noop)
+		r.Get("/u/{name}", noop)
+		if code := serve(r, http.MethodGet, "/u/1"); code != http.StatusOK {
+			t.Fatalf("GET = %d, want 200", code)
+		}
+		// the warned route was recorded, a copy of it is a duplicate
+		_, err := r.TryHandle([]string{http.MethodGet}, "/u/{name}", noop)
+		var ce *ConflictError
+		if !errors.As(err, &ce) || ce.Kind != ConflictDuplicate || ce.Existing != "/u/{name}" {
+			t.Fatalf("copy of the warned route = %v, want a duplicate of /u/{name}", err)
+		}
+	})
+
+	t.Run("ignore registers", func(t *testing.T) {
+		r := New(WithConflictPolicy(ConflictIgnore))
+		r.AccessLog = false
+		r.Get("/a", func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusTeapot) })
+		r.Get("/a", noop)
+		// the duplicate replaced the first handler
+		if code := serve(r, http.MethodGet, "/a"); code != http.StatusOK {
+			t.Fatalf("GET /a = %d, want the second handler", code)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		r := New(WithConflictPolicy(ConflictPanic))
+		r.Get("/users/:id", noop)
+		defer func() {
+			err, _ := recover().(error)
+			var ce *ConflictError
+			if !errors.As(err, &ce) || ce.Kind != ConflictShadowed {
+				t.Fatalf("recovered %v, want a shadowed ConflictError", err)
+			}
+		}()
+		r.Get("/users/{name}", noop)
+	})
+
+	t.Run("try handle does not register", func(t *testing.T) {
+		r := New()
+		r.AccessLog = false
+		r.Get("/u/:id", func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusTeapot) })
+		if _, err := r.TryHandle([]string{http.MethodGet}, "/u/{name}", noop); err == nil {
+			t.Fatal("TryHandle returned no conflict")
+		}
+		if code := serve(r, http.MethodGet, "/u/1"); code != http.StatusTeapot {
+			t.Fatalf("GET after a refused TryHandle = %d, want the first handler", code)
+		}
+		// the refused route was not recorded
+		if _, err := r.TryHandle([]string{http.MethodGet}, "/u/{other}", noop); err == nil {
+			t.Fatal("no conflict with the first route")
+		} else if ce := err.(*ConflictError); ce.Existing != "/u/:id" {
+			t.Fatalf("conflict with %s, want /u/:id", ce.Existing)
+		}
+	})
+
+	t.Run("groups are checked", func(t *testing.T) {
+		r := New()
+		r.Get("/api/items/:id", noop)
+		var err error
+		r.Group("/api", func(gr *Router) {
+			_, err = gr.TryHandle([]string{http.MethodGet}, "/items/{itemID}", noop)
+		})
+		if !errors.Is(err, ErrRouteConflict) {
+			t.Fatalf("group TryHandle error = %v, want a conflict", err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// constraint limits the values a {param:constraint} segment accepts
+type constraint struct {
+	// raw text after the colon, e.g. "int" or "[a-z-]+"
+	expr  string
+	match func(string) bool
+}
+
+var (
+	constraintsMU sync.RWMutex
+	// built-in constraint types, used as {id:int}
+	constraints = map[string]func(string) bool{
+		"int": func(s string) bool {
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err == nil
+		},
+		"uint": func(s string) bool {
+			_, err := strconv.ParseUint(s, 10, 64)
+			return err == nil
+		},
+		"uuid": func(s string) bool {
+			_, err := uuid.Parse(s)
+			return err == nil && len(s) == 36
+		},
+		"alpha": func(s string) bool {
+			for i := 0; i < len(s); i++ {
+				c := s[i]
+				if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+					return false
+				}
+			}
+			return s != ""
+		},
+		"date": func(s string) bool {
+			_, err := time.Parse(time.DateOnly, s)
+			return err == nil
+		},
+	}
+)
+
+// RegisterConstraint adds a named constraint type usable in patterns as
+// {param:name}, it replaces a built-in type with the same name
+//
+// Example:
+//
+//	routes.RegisterConstraint("hex", func(s string) bool { ... })
+//	r.Get("/colors/{code:hex}", handler)
+func RegisterConstraint(name string, match func(string) bool) {
+	constraintsMU.Lock()
+	constraints[name] = match
+	constraintsMU.Unlock()
+}
+
+// compileConstraint returns the named constraint type for expr, or a regex
+// constraint anchored to the whole parameter value
+func compileConstraint(expr string) *constraint {
+	constraintsMU.RLock()
+	fn, ok := constraints[expr]
+	constraintsMU.RUnlock()
+	if ok {
+		return &constraint{expr: expr, match: fn}
+	}
+
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		panic("routes: invalid constraint " + strconv.Quote(expr) + ": " + err.Error())
+	}
+	return &constraint{expr: expr, match: re.MatchString}
+}
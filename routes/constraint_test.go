@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConstraintMatch(t *testing.T) {
+	tests := []struct {
+		expr  string
+		value string
+		want  bool
+	}{
+		{"int", "42", true},
+		{"int", "-7", true},
+		{"int", "4x", false},
+		{"int", "", false},
+		{"uint", "42", true},
+		{"uint", "-7", false},
+		{"uuid", "0b8f6d2e-8a55-4c2b-9a53-6a1f0f5f3c1d", true},
+		{"uuid", "0b8f6d2e8a554c2b9a536a1f0f5f3c1d", false},
+		{"uuid", "not-a-uuid", false},
+		{"alpha", "abcXYZ", true},
+		{"alpha", "abc1", false},
+		{"alpha", "", false},
+		{"date", "2024-02-29", true},
+		{"date", "2023-02-29", false},
+		{"date", "2024-2-1", false},
+		// regex constraints match the whole value
+		{"[a-z-]+", "hello-world", true},
+		{"[a-z-]+", "Hello", false},
+		{"[a-z-]+", "abc1", false},
+		{"v[0-9]+", "v12", true},
+		{"v[0-9]+", "xv12", false},
+	}
+	for _, tt := range tests {
+		if got := compileConstraint(tt.expr).match(tt.value); got != tt.want {
+			t.Errorf("{:%s} match %q = %v, want %v", tt.expr, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestConstraintInvalidRegex(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("invalid regex constraint did not panic")
+		}
+	}()
+	compileConstraint("[a-")
+}
+
+func TestRegisterConstraint(t *testing.T) {
+	RegisterConstraint("hex", func(s string) bool {
+		for i := 0; i < len(s); i++ {
+			c := s[i]
+			if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+				return false
+			}
+		}
+		return s != ""
+	})
+	root := buildTree("/colors/{code:hex}")
+	if lf, params, _ := root.lookup("/colors/ff00aa", http.MethodGet); lf == nil || params["code"] != "ff00aa" {
+		t.Fatalf("/colors/ff00aa: leaf %v, params %v", lf, params)
+	}
+	if lf, _, _ := root.lookup("/colors/red", http.MethodGet); lf != nil {
+		t.Fatalf("/colors/red matched %q", lf.pattern.pattern)
+	}
+}
+
+func TestConstraintPrecedence(t *testing.T) {
+	// registered out of order on purpose, constrained parameters are tried
+	// before the unconstrained one
+	root := buildTree(
+		"/items/{name}",
+		"/items/{id:int}",
+		"/items/{slug:[a-z]+-[a-z]+}",
+		"/items/{id:uuid}/detail",
+		"/items/{any}/detail",
+		"/static/*filepath",
+		"/static/{file:[a-z]+}.css",
+	)
+	tests := []struct {
+		path    string
+		pattern string
+		param   string
+		value   string
+	}{
+		{"/items/42", "/items/{id:int}", "id", "42"},
+		{"/items/red-shoe", "/items/{slug:[a-z]+-[a-z]+}", "slug", "red-shoe"},
+		{"/items/Shoe", "/items/{name}", "name", "Shoe"},
+		{"/items/0b8f6d2e-8a55-4c2b-9a53-6a1f0f5f3c1d/detail", "/items/{id:uuid}/detail", "id", "0b8f6d2e-8a55-4c2b-9a53-6a1f0f5f3c1d"},
+		{"/items/42/detail", "/items/{any}/detail", "any", "42"},
+		// catch-all only when nothing more specific matches
+		{"/static/app.css", "/static/{file:[a-z]+}.css", "file", "app"},
+		{"/static/App.css", "/static/*filepath", "filepath", "App.css"},
+		{"/static/css/app.css", "/static/*filepath", "filepath", "css/app.css"},
+	}
+	for _, tt := range tests {
+		lf, params, _ := root.lookup(tt.path, http.MethodGet)
+		if lf == nil {
+			t.Errorf("%s: no match, want %s", tt.path, tt.pattern)
+			continue
+		}
+		if lf.pattern.pattern != tt.pattern || params[tt.param] != tt.value {
+			t.Errorf("%s: matched %s %v, want %s with %s=%q", tt.path, lf.pattern.pattern, params, tt.pattern, tt.param, tt.value)
+		}
+	}
+}
+
+func TestConstraintRejected(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	r.Get("/users/{id:int}", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/days/{day:date}", func(w http.ResponseWriter, req *http.Request) {})
+
+	for path, want := range map[string]int{
+		"/users/12":         http.StatusOK,
+		"/users/abc":        http.StatusNotFound,
+		"/days/2024-01-31":  http.StatusOK,
+		"/days/2024-01-32":  http.StatusNotFound,
+		"/days/yesterday/x": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
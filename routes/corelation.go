@@ -0,0 +1,115 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/he-end/simproute/goruntime"
+	logger "github.com/he-end/simproute/route_logger"
+	"github.com/he-end/simproute/tracing"
+	"go.uber.org/zap"
+)
+
+// CorelationConfig controls the id set by AutoCorelation
+type CorelationConfig struct {
+	// InboundHeaders are checked in order, the first valid value is reused
+	// as correlation id. For "traceparent" the trace id is reused.
+	InboundHeaders []string
+
+	// ResponseHeader carries the id back to the client
+	// default "X-Set-Corelation-ID"
+	ResponseHeader string
+
+	// Generator creates the id when no inbound header has one
+	// default goruntime.NewUUIDv4
+	Generator goruntime.IDGenerator
+
+	// LegacyGoroutineBinding also binds the id to the handler goroutine for
+	// the deprecated goroutine keyed lookups, goruntime.GetCorelationID and
+	// the runtime fields of logger.Info. It reads the goroutine id from
+	// runtime.Stack several times per request, leave it off unless old handlers
+	// still call them.
+	LegacyGoroutineBinding bool
+}
+
+// DefaultCorelationConfig is the config used by New
+func DefaultCorelationConfig() CorelationConfig {
+	return CorelationConfig{
+		InboundHeaders: []string{"X-Request-ID", "X-Correlation-ID", "traceparent"},
+		ResponseHeader: "X-Set-Corelation-ID",
+		Generator:      goruntime.NewUUIDv4,
+	}
+}
+
+// inboundID returns the first valid id found in the inbound headers
+func (c *CorelationConfig) inboundID(h http.Header) string {
+	for _, name := range c.InboundHeaders {
+		v := strings.TrimSpace(h.Get(name))
+		if v == "" {
+			continue
+		}
+		if strings.EqualFold(name, tracing.HeaderTraceparent) {
+			sc, err := tracing.ParseTraceparent(v)
+			if err != nil {
+				continue
+			}
+			v = sc.TraceID.String()
+		}
+		if validCorelationID(v) {
+			return v
+		}
+	}
+	return ""
+}
+
+// validCorelationID accepts 1 to 128 characters of [A-Za-z0-9._:-]
+// so an inbound id cannot break log lines or response headers
+func validCorelationID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// mwAutoCorelation stores the correlation id in the request context,
+// read it with goruntime.FromContext and log with logger.Ctx
+func mwAutoCorelation(cfg CorelationConfig) func(http.Handler) http.Handler {
+	if cfg.ResponseHeader == "" {
+		cfg.ResponseHeader = "X-Set-Corelation-ID"
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = goruntime.NewUUIDv4
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idCorelate := cfg.inboundID(r.Header)
+			if idCorelate == "" {
+				idCorelate = cfg.Generator()
+			}
+			w.Header().Set(cfg.ResponseHeader, idCorelate)
+			ctx := goruntime.NewContext(r.Context(), idCorelate)
+			ctx = logger.WithFields(ctx, zap.String("request_id", idCorelate))
+
+			if cfg.LegacyGoroutineBinding {
+				// released when the handler returns
+				unbind := goruntime.BindGoroutine(idCorelate)
+				logger.NewLoggerOnRuntime(logger.RegisterRuntime{Key: "request_id", Value: idCorelate})
+				defer func() {
+					unbind()
+					logger.DeferDeleteRuntimeValue()
+				}()
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
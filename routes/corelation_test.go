@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/he-end/simproute/goruntime"
+	logger "github.com/he-end/simproute/route_logger"
+)
+
+func TestCorelationContext(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	var fromCtx string
+	r.Get("/", func(w http.ResponseWriter, req *http.Request) {
+		fromCtx = goruntime.FromContext(req.Context())
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	header := rec.Header().Get("X-Set-Corelation-ID")
+	if header == "" || fromCtx != header {
+		t.Fatalf("context id %q, header %q", fromCtx, header)
+	}
+
+	// an inbound id is reused, an invalid one is replaced
+	for in, reused := range map[string]bool{"abc-123": true, "bad id\n": false} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Request-ID", in)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if got := rec.Header().Get("X-Set-Corelation-ID"); (got == in) != reused {
+			t.Errorf("inbound %q gave %q", in, got)
+		}
+	}
+}
+
+// with LegacyGoroutineBinding the deprecated goroutine keyed lookups see the
+// id of the request while the handler runs and nothing after it
+func TestCorelationGoroutineShim(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	r.Corelation.LegacyGoroutineBinding = true
+	var inHandler uuid.UUID
+	var runtimeValue *logger.RegisterRuntime
+	r.Get("/", func(w http.ResponseWriter, req *http.Request) {
+		inHandler = goruntime.GetCorelationID()
+		runtimeValue = logger.GetLoggerRuntimeStore()
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	header := rec.Header().Get("X-Set-Corelation-ID")
+	if inHandler.String() != header {
+		t.Fatalf("GetCorelationID in handler = %s, header %s", inHandler, header)
+	}
+	if runtimeValue == nil || runtimeValue.Value != header {
+		t.Fatalf("logger runtime value = %v, want %s", runtimeValue, header)
+	}
+
+	// httptest runs the handler on this goroutine, the router cleared it
+	// and a miss generates a new id
+	id := goruntime.GetCorelationID()
+	defer goruntime.ClearCorelationID()
+	if id == uuid.Nil || id.String() == header {
+		t.Fatalf("GetCorelationID after the request = %s, want a new id", id)
+	}
+	if again := goruntime.GetCorelationID(); again != id {
+		t.Fatalf("GetCorelationID = %s then %s, want the generated id kept", id, again)
+	}
+	if logger.GetLoggerRuntimeStore() != nil {
+		t.Fatal("logger runtime value kept after the request")
+	}
+}
+
+// without LegacyGoroutineBinding nothing is bound to the handler goroutine
+func TestCorelationNoGoroutineBinding(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	var runtimeValue *logger.RegisterRuntime
+	r.Get("/", func(w http.ResponseWriter, req *http.Request) {
+		runtimeValue = logger.GetLoggerRuntimeStore()
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if runtimeValue != nil {
+		t.Fatalf("logger runtime value = %v, want none", runtimeValue)
+	}
+}
+
+func TestCorelationInbound(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	long := strings.Repeat("a", 128)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string // "" when a new id is generated
+	}{
+		{"request id", map[string]string{"X-Request-ID": "req-1"}, "req-1"},
+		{"all allowed characters", map[string]string{"X-Request-ID": "A-z_0.9:x"}, "A-z_0.9:x"},
+		{"trimmed", map[string]string{"X-Request-ID": "  req-1 "}, "req-1"},
+		{"128 characters", map[string]string{"X-Request-ID": long}, long},
+		{"too long", map[string]string{"X-Request-ID": long + "a"}, ""},
+		{"space", map[string]string{"X-Request-ID": "req 1"}, ""},
+		{"quote", map[string]string{"X-Request-ID": `req"1`}, ""},
+		{"non ascii", map[string]string{"X-Request-ID": "réq"}, ""},
+		{"invalid falls to correlation id", map[string]string{"X-Request-ID": "bad id", "X-Correlation-ID": "corr-2"}, "corr-2"},
+		{"request id first", map[string]string{"X-Request-ID": "req-1", "X-Correlation-ID": "corr-2"}, "req-1"},
+		{"traceparent trace id", map[string]string{"traceparent": traceparent}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"invalid request id falls to traceparent", map[string]string{"X-Request-ID": "a b", "traceparent": traceparent}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"invalid traceparent", map[string]string{"traceparent": "00-zz-00f067aa0ba902b7-01"}, ""},
+		{"zero trace id", map[string]string{"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01"}, ""},
+	}
+
+	r := New(WithCorelation(CorelationConfig{
+		InboundHeaders: DefaultCorelationConfig().InboundHeaders,
+		Generator:      func() string { return "generated" },
+	}))
+	r.AccessLog = false
+	var fromCtx string
+	r.Get("/", func(w http.ResponseWriter, req *http.Request) {
+		fromCtx = goruntime.FromContext(req.Context())
+	})
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		want := tt.want
+		if want == "" {
+			want = "generated"
+		}
+		if got := rec.Header().Get("X-Set-Corelation-ID"); got != want || fromCtx != want {
+			t.Errorf("%s: header %q, context %q, want %q", tt.name, got, fromCtx, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	logger "github.com/he-end/simproute/route_logger"
+	"github.com/he-end/simproute/routes/binding"
+	"github.com/he-end/simproute/routes/response"
+	"github.com/he-end/simproute/routes/validate"
+	"go.uber.org/zap"
+)
+
+// ErrorHandlerFunc is a handler returning its failure instead of writing it,
+// adapt it with ErrorHandler
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ErrorHandler adapts fn to a HandlerFunc answering the returned error
+// with WriteError
+//
+// Example:
+//
+//	r.Get("/users/{id:int}", routes.ErrorHandler(func(w http.ResponseWriter, r *http.Request) error {
+//		user, err := repo.Find(r.Context(), id) // sql.ErrNoRows -> 404
+//		if err != nil {
+//			return err
+//		}
+//		response.For(r).Success(w, response.MsgUserDataRetrieved, user)
+//		return nil
+//	}))
+func ErrorHandler(fn ErrorHandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			WriteError(w, r, err)
+		}
+	}
+}
+
+// WriteError answers err with the response envelope:
+//   - binding and validation errors as 400 (or 415)
+//   - a response.HTTPError, or an error registered with
+//     response.RegisterError, with its status and code
+//   - anything else as 500
+//
+// 5xx errors are logged with the request logger (and its request_id).
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	var be *binding.Error
+	var ve validate.Errors
+	if errors.As(err, &ve) {
+		response.For(r).Fail(w, response.MsgValidationError, response.ErrCodeValidationError, ve.Error(), ve...)
+		return
+	}
+	if errors.As(err, &be) {
+		response.For(r).Error(w, "Invalid request", be.Code, be.Error(), be.Status)
+		return
+	}
+
+	he := response.ErrorOf(err)
+	if he == nil {
+		he = response.NewError(http.StatusInternalServerError, response.ErrCodeInternalError, response.MsgInternalError).Wrap(err)
+	}
+	if he.Status >= http.StatusInternalServerError {
+		logger.Ctx(r.Context()).Error("handler error", zap.Error(err), zap.Int("status", he.Status))
+	}
+	// the cause is only shown for 5xx, ResponseHandler.Error redacts it
+	// outside dev mode
+	details := he.Details
+	if details == "" && he.Err != nil && he.Status >= http.StatusInternalServerError {
+		details = he.Err.Error()
+	}
+	response.For(r).ErrorDetail(w, he.Status, he.Message, response.ErrorInfo{
+		Code:       he.Code,
+		Details:    details,
+		Extensions: he.Extensions,
+	})
+}
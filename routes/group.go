@@ -2,43 +2,52 @@ package routes
 
 import (
 	"net/http"
-	"strings"
 )
 
+// Group registers the routes added in fn under prefix.
+// Middleware added with gr.Use wraps only the routes of this group
+// (and its nested groups), the parent middleware stays outer-most.
 func (r *Router) Group(prefix string, fn func(gr *Router)) {
 	if prefix == "" || prefix[0] != '/' {
 		prefix = "/"
 	}
 
 	group := &Router{
-		Routes: make(map[string]map[string]http.Handler),
-		DynamicRoutes: make([]struct {
-			pattern routePattern
-			method  map[string]http.Handler
-		}, 0, 4),
+		Routes:         make(map[string]map[string]http.Handler),
 		Mws:            nil,
 		Prefix:         joinPrefix(r.Prefix, prefix),
 		AutoCorelation: r.AutoCorelation,
 		RecoverOnPanic: r.RecoverOnPanic,
+		grouped:        true,
+		root:           r.top(),
 	}
 	fn(group)
 
-	r.MU.Lock()
-	defer r.MU.Unlock()
+	group.MU.RLock()
+	mws, routes := group.Mws, group.routes
+	group.MU.RUnlock()
 
-	for p, method := range group.Routes {
-		if r.Routes[p] == nil {
-			r.Routes[p] = make(map[string]http.Handler)
-		}
-		for m, h := range method {
-			method := strings.ToUpper(strings.TrimSpace(m))
-			if method == "" {
-				continue
-			}
-			r.Routes[p][method] = h
-		}
+	for _, rt := range routes {
+		rt.mws = append(append([]func(http.Handler) http.Handler(nil), mws...), rt.mws...)
+		rt.scopeMws = append(append([]func(http.Handler) http.Handler(nil), mws...), rt.scopeMws...)
+		r.register(rt)
+	}
+}
+
+// With returns a router that registers routes on r wrapped with mws
+//
+// Example:
+//
+//	r.With(authMw).Get("/admin/stats", StatsHandler)
+func (r *Router) With(mws ...func(http.Handler) http.Handler) *Router {
+	return &Router{
+		Routes:         make(map[string]map[string]http.Handler),
+		Mws:            append([]func(http.Handler) http.Handler(nil), mws...),
+		Prefix:         r.Prefix,
+		AutoCorelation: r.AutoCorelation,
+		RecoverOnPanic: r.RecoverOnPanic,
+		parent:         r,
 	}
-	r.DynamicRoutes = append(r.DynamicRoutes, group.DynamicRoutes...)
 }
 
 func joinPrefix(a, b string) string {
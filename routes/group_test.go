@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// record appends name to the X-Order header of the request
+func record(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.Header.Add("X-Order", name)
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// middleware runs global -> group -> nested group -> With -> route, and the
+// middleware of a group stays inside it
+func TestGroupMiddlewareOrder(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	var order []string
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		order = req.Header.Values("X-Order")
+	}
+
+	r.Use(record("global"))
+	r.Get("/root", handler, record("route"))
+	r.Group("/api", func(api *Router) {
+		api.Use(record("api"))
+		api.With(record("with")).Get("/users", handler, record("route"))
+		api.Group("/admin", func(admin *Router) {
+			admin.Use(record("admin"))
+			admin.Get("/stats", handler)
+		})
+		api.Get("/after", handler)
+	})
+	r.Group("/public", func(pub *Router) {
+		pub.Get("/items", handler)
+	})
+	// added after the groups, still the outer-most
+	r.Use(record("global2"))
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/root", []string{"global", "global2", "route"}},
+		{"/api/users", []string{"global", "global2", "api", "with", "route"}},
+		{"/api/admin/stats", []string{"global", "global2", "api", "admin"}},
+		{"/api/after", []string{"global", "global2", "api"}},
+		{"/public/items", []string{"global", "global2"}},
+	}
+	for _, tt := range tests {
+		order = nil
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d", tt.path, rec.Code)
+		}
+		if !reflect.DeepEqual(order, tt.want) {
+			t.Errorf("GET %s order = %s, want %s", tt.path, strings.Join(order, " -> "), strings.Join(tt.want, " -> "))
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Package i18n keeps translated messages per locale and picks the locale of
+// a request from its Accept-Language header
+//
+//	c := i18n.New("en")
+//	c.LoadFS(locales, "locales/*.json")
+//	msg := c.T(r.Header.Get("Accept-Language"), "ITEMS_LEFT", map[string]any{"count": 3})
+//
+// A message is a plain text or a set of plural forms ("zero", "one", "two",
+// "few", "many", "other"), {name} in the text is replaced by the param name
+// and "count" selects the plural form.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Catalog holds the messages of every locale
+type Catalog struct {
+	mu sync.RWMutex
+	// locale -> key -> plural forms, a plain text is stored as "other"
+	messages  map[string]map[string]map[string]string
+	fallbacks map[string][]string
+	def       string
+}
+
+// New creates an empty catalog, def is the locale used when the request
+// accepts none of the loaded ones
+func New(def string) *Catalog {
+	return &Catalog{
+		messages:  make(map[string]map[string]map[string]string),
+		fallbacks: make(map[string][]string),
+		def:       normalize(def),
+	}
+}
+
+// DefaultLocale returns the last locale of every fallback chain
+func (c *Catalog) DefaultLocale() string {
+	return c.def
+}
+
+// Add sets the text of key for locale
+func (c *Catalog) Add(locale, key, text string) {
+	c.AddPlural(locale, key, map[string]string{"other": text})
+}
+
+// AddPlural sets the plural forms of key for locale, "other" is used for
+// the categories without a form
+//
+// Example:
+//
+//	c.AddPlural("en", "ITEMS_LEFT", map[string]string{
+//		"one":   "{count} item left",
+//		"other": "{count} items left",
+//	})
+func (c *Catalog) AddPlural(locale, key string, forms map[string]string) {
+	locale = normalize(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	msgs := c.messages[locale]
+	if msgs == nil {
+		msgs = make(map[string]map[string]string)
+		c.messages[locale] = msgs
+	}
+	msgs[key] = forms
+}
+
+// SetFallback sets the locales tried after locale before its base language
+// and the default, e.g. SetFallback("ms", "id") answers Malay with Indonesian
+func (c *Catalog) SetFallback(locale string, fallbacks ...string) {
+	// a copy, the caller keeps its slice
+	chain := make([]string, len(fallbacks))
+	for i, f := range fallbacks {
+		chain[i] = normalize(f)
+	}
+	c.mu.Lock()
+	c.fallbacks[normalize(locale)] = chain
+	c.mu.Unlock()
+}
+
+// Locales returns the loaded locales, sorted
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locales := make([]string, 0, len(c.messages))
+	for l := range c.messages {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Match returns the fallback chain for an Accept-Language header, the
+// accepted tags by preference, each followed by its fallbacks and base
+// language, then the default locale
+//
+// Example: "pt-BR, ms;q=0.8" with SetFallback("ms", "id") gives
+// [pt-br pt ms id en]
+func (c *Catalog) Match(acceptLanguage string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var chain []string
+	seen := make(map[string]bool)
+	var add func(string)
+	add = func(tag string) {
+		for tag != "" {
+			if !seen[tag] {
+				seen[tag] = true
+				chain = append(chain, tag)
+				for _, f := range c.fallbacks[tag] {
+					add(f)
+				}
+			}
+			i := strings.LastIndexByte(tag, '-')
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		add(tag)
+	}
+	add(c.def)
+	return chain
+}
+
+// Lookup returns the message of key in the first locale of chain having it,
+// with params interpolated. locale is the one the message came from
+func (c *Catalog) Lookup(chain []string, key string, params map[string]any) (text, locale string, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, l := range chain {
+		forms, found := c.messages[l][key]
+		if !found {
+			continue
+		}
+		form := forms["other"]
+		if n, has := count(params); has {
+			if f, exists := forms[Plural(l, n)]; exists {
+				form = f
+			}
+		}
+		return interpolate(form, params), l, true
+	}
+	return "", "", false
+}
+
+// T returns the message of key for the Accept-Language header, key itself
+// when no locale of the chain has it
+func (c *Catalog) T(acceptLanguage, key string, params map[string]any) string {
+	if text, _, ok := c.Lookup(c.Match(acceptLanguage), key, params); ok {
+		return text
+	}
+	return key
+}
+
+// parseAcceptLanguage returns the accepted tags by preference,
+// "*" and tags with q=0 are dropped
+func parseAcceptLanguage(header string) []string {
+	type tagQ struct {
+		tag string
+		q   float64
+	}
+	var tags []tagQ
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = normalize(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(p, "=")
+			if strings.TrimSpace(name) == "q" {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, tagQ{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.tag
+	}
+	return out
+}
+
+// normalize lowercases a language tag and uses '-' as separator,
+// "pt_BR" gives "pt-br"
+func normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// interpolate replaces {name} with the param name, unknown names are kept
+func interpolate(text string, params map[string]any) string {
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(text, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(text[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(text[:start])
+		if v, ok := params[text[start+1:end]]; ok {
+			fmt.Fprint(&b, v)
+		} else {
+			b.WriteString(text[start : end+1])
+		}
+		text = text[end+1:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+// count returns the "count" param as a number
+func count(params map[string]any) (float64, bool) {
+	switch n := params["count"].(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	c := New("EN")
+	c.SetFallback("ms", "id")
+	c.SetFallback("pt_BR", "es")
+
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{"en"}},
+		{"*", []string{"en"}},
+		{"fr", []string{"fr", "en"}},
+		{"pt-BR, ms;q=0.8", []string{"pt-br", "es", "pt", "ms", "id", "en"}},
+		{"ms;q=0.5, ja", []string{"ja", "ms", "id", "en"}},
+		{"de;q=0, en-GB", []string{"en-gb", "en"}},
+		{"ID , id", []string{"id", "en"}},
+		{"zh-Hant-TW", []string{"zh-hant-tw", "zh-hant", "zh", "en"}},
+	}
+	for _, tt := range tests {
+		if got := c.Match(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSetFallbackCopies(t *testing.T) {
+	c := New("en")
+	fallbacks := []string{"ID"}
+	c.SetFallback("ms", fallbacks...)
+	if fallbacks[0] != "ID" {
+		t.Errorf("SetFallback changed the caller slice to %v", fallbacks)
+	}
+	fallbacks[0] = "ja"
+	if got := c.Match("ms"); !reflect.DeepEqual(got, []string{"ms", "id", "en"}) {
+		t.Errorf("Match = %v after the caller slice changed", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c := New("en")
+	c.Add("en", "HELLO", "Hello {name}")
+	c.Add("en", "ONLY_EN", "english")
+	c.Add("id", "HELLO", "Halo {name}")
+	c.AddPlural("en", "ITEMS", map[string]string{"one": "{count} item", "other": "{count} items"})
+	c.AddPlural("fr", "ITEMS", map[string]string{"one": "{count} article", "other": "{count} articles"})
+
+	tests := []struct {
+		header, key string
+		params      map[string]any
+		text        string
+		locale      string
+	}{
+		{"id", "HELLO", map[string]any{"name": "Budi"}, "Halo Budi", "id"},
+		{"id-ID", "HELLO", map[string]any{"name": "Budi"}, "Halo Budi", "id"},
+		{"id", "ONLY_EN", nil, "english", "en"},
+		{"en", "HELLO", map[string]any{"other": 1}, "Hello {name}", "en"},
+		{"en", "ITEMS", map[string]any{"count": 1}, "1 item", "en"},
+		{"en", "ITEMS", map[string]any{"count": uint8(3)}, "3 items", "en"},
+		{"en", "ITEMS", nil, "{count} items", "en"},
+		{"fr", "ITEMS", map[string]any{"count": 0}, "0 article", "fr"},
+		{"fr", "ITEMS", map[string]any{"count": 1.5}, "1.5 article", "fr"},
+		{"fr", "ITEMS", map[string]any{"count": int64(2)}, "2 articles", "fr"},
+	}
+	for _, tt := range tests {
+		text, locale, ok := c.Lookup(c.Match(tt.header), tt.key, tt.params)
+		if !ok || text != tt.text || locale != tt.locale {
+			t.Errorf("Lookup(%s, %s, %v) = %q %q %v, want %q %q", tt.header, tt.key, tt.params, text, locale, ok, tt.text, tt.locale)
+		}
+	}
+
+	if _, _, ok := c.Lookup(c.Match("id"), "MISSING", nil); ok {
+		t.Error("Lookup found a missing key")
+	}
+	if got := c.T("id", "MISSING", nil); got != "MISSING" {
+		t.Errorf("T = %q, want the key", got)
+	}
+	if got := c.Locales(); !reflect.DeepEqual(got, []string{"en", "fr", "id"}) {
+		t.Errorf("Locales = %v", got)
+	}
+}
+
+func TestDefaultCatalog(t *testing.T) {
+	c := Default()
+	if c.DefaultLocale() != "en" {
+		t.Errorf("DefaultLocale = %q", c.DefaultLocale())
+	}
+	for _, locale := range []string{"en", "id", "ja"} {
+		if _, got, ok := c.Lookup([]string{locale}, "validate.required", nil); !ok || got != locale {
+			t.Errorf("validate.required missing in %s", locale)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package i18n
+
+import (
+	"embed"
+	"sync"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var (
+	defaultOnce    sync.Once
+	defaultCatalog *Catalog
+)
+
+// Default returns the shared catalog with the built-in English, Indonesian
+// and Japanese messages of the response error codes and of the validate
+// rules ("validate.<rule>"), English is the default locale. Messages added
+// to it are used by the response package.
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		defaultCatalog = New("en")
+		if err := defaultCatalog.LoadFS(locales, "locales/*.json"); err != nil {
+			panic(err)
+		}
+	})
+	return defaultCatalog
+}
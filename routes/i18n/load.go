@@ -0,0 +1,209 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// LoadJSON adds the messages of a JSON object to locale, a value is the
+// text or an object of plural forms
+//
+//	{
+//		"NO_DATA": "Data tidak ditemukan",
+//		"ITEMS_LEFT": {"one": "{count} item left", "other": "{count} items left"}
+//	}
+func (c *Catalog) LoadJSON(locale string, data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("i18n: %s: %w", locale, err)
+	}
+	for key, value := range raw {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			c.Add(locale, key, text)
+			continue
+		}
+		var forms map[string]string
+		if err := json.Unmarshal(value, &forms); err != nil {
+			return fmt.Errorf("i18n: %s: %s is neither a text nor plural forms", locale, key)
+		}
+		c.AddPlural(locale, key, forms)
+	}
+	return nil
+}
+
+// LoadTOML adds the messages of a TOML document to locale, a key is set to
+// the text or a table holds its plural forms
+//
+//	NO_DATA = "データが見つかりません"
+//
+//	[ITEMS_LEFT]
+//	other = "残り{count}個"
+//
+// Only this subset of TOML is read: comments, bare or quoted keys, basic
+// and literal strings and one level of tables.
+func (c *Catalog) LoadTOML(locale string, data []byte) error {
+	var table string
+	forms := make(map[string]map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.LastIndexByte(line, ']')
+			if end < 0 {
+				return fmt.Errorf("i18n: %s: line %d: unclosed table", locale, i+1)
+			}
+			name, err := tomlKey(strings.TrimSpace(line[1:end]))
+			if err != nil {
+				return fmt.Errorf("i18n: %s: line %d: %w", locale, i+1, err)
+			}
+			table = name
+			if forms[table] == nil {
+				forms[table] = make(map[string]string)
+			}
+			continue
+		}
+		k, v, ok := cutKeyValue(line)
+		if !ok {
+			return fmt.Errorf("i18n: %s: line %d: expected key = value", locale, i+1)
+		}
+		key, err := tomlKey(strings.TrimSpace(k))
+		if err != nil {
+			return fmt.Errorf("i18n: %s: line %d: %w", locale, i+1, err)
+		}
+		value, err := tomlString(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("i18n: %s: line %d: %w", locale, i+1, err)
+		}
+		if table == "" {
+			c.Add(locale, key, value)
+		} else {
+			forms[table][key] = value
+		}
+	}
+	for key, f := range forms {
+		c.AddPlural(locale, key, f)
+	}
+	return nil
+}
+
+// LoadFS loads every file matching patterns, e.g. "locales/*.json", the
+// locale is the file name without extension ("pt-BR.toml" gives pt-br)
+//
+// Example:
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	err := catalog.LoadFS(locales, "locales/*.json", "locales/*.toml")
+func (c *Catalog) LoadFS(fsys fs.FS, patterns ...string) error {
+	for _, pattern := range patterns {
+		files, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			data, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return err
+			}
+			ext := path.Ext(file)
+			locale := strings.TrimSuffix(path.Base(file), ext)
+			switch ext {
+			case ".json":
+				err = c.LoadJSON(locale, data)
+			case ".toml":
+				err = c.LoadTOML(locale, data)
+			default:
+				err = fmt.Errorf("i18n: %s: unsupported file type", file)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tomlKey returns a bare or quoted key
+func tomlKey(s string) (string, error) {
+	if s == "" {
+		return "", fmt.Errorf("empty key")
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		return tomlString(s)
+	}
+	for _, c := range s {
+		if c != '_' && c != '-' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return "", fmt.Errorf("invalid bare key %q", s)
+		}
+	}
+	return s, nil
+}
+
+// cutKeyValue splits a key = value line at the first '=' after the key,
+// a quoted key may contain '='
+func cutKeyValue(line string) (key, value string, ok bool) {
+	start := 0
+	if line[0] == '"' || line[0] == '\'' {
+		end := closingQuote(line)
+		if end < 0 {
+			return "", "", false
+		}
+		start = end + 1
+	}
+	i := strings.IndexByte(line[start:], '=')
+	if i < 0 {
+		return "", "", false
+	}
+	return line[:start+i], line[start+i+1:], true
+}
+
+// closingQuote returns the index of the quote closing the string s starts
+// with, -1 when it is not closed
+func closingQuote(s string) int {
+	if s[0] == '\'' {
+		if end := strings.IndexByte(s[1:], '\''); end >= 0 {
+			return end + 1
+		}
+		return -1
+	}
+	for end := 1; end < len(s); end++ {
+		if s[end] == '\\' {
+			end++
+		} else if s[end] == '"' {
+			return end
+		}
+	}
+	return -1
+}
+
+// tomlString returns a basic ("...") or literal ('...') string, a comment
+// may follow it
+func tomlString(s string) (string, error) {
+	if len(s) < 2 || (s[0] != '"' && s[0] != '\'') {
+		return "", fmt.Errorf("expected a string, got %q", s)
+	}
+	end := closingQuote(s)
+	if end < 0 {
+		return "", fmt.Errorf("unclosed string %s", s)
+	}
+	value := s[1:end]
+	if s[0] == '"' {
+		v, err := strconv.Unquote(s[:end+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", s[:end+1])
+		}
+		value = v
+	}
+	if rest := strings.TrimSpace(s[end+1:]); rest != "" && rest[0] != '#' {
+		return "", fmt.Errorf("unexpected %q after string", rest)
+	}
+	return value, nil
+}
@@ -0,0 +1,96 @@
+package i18n
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadTOML(t *testing.T) {
+	c := New("en")
+	err := c.LoadTOML("ja", []byte(`
+# comment
+NO_DATA = "データが見つかりません" # trailing comment
+"a=b" = "quoted key with ="
+'lit=eral' = 'C:\path'
+escaped = "say \"hi\" = ok"
+bare-key_1 = "x"
+
+[ITEMS_LEFT]
+other = "残り{count}個"
+
+["WITH SPACE"]
+one = "1"
+other = "n"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]string{
+		"NO_DATA":    {"other": "データが見つかりません"},
+		"a=b":        {"other": "quoted key with ="},
+		"lit=eral":   {"other": `C:\path`},
+		"escaped":    {"other": `say "hi" = ok`},
+		"bare-key_1": {"other": "x"},
+		"ITEMS_LEFT": {"other": "残り{count}個"},
+		"WITH SPACE": {"one": "1", "other": "n"},
+	}
+	if got := c.messages["ja"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("messages = %v, want %v", got, want)
+	}
+}
+
+func TestLoadTOMLErrors(t *testing.T) {
+	tests := []struct{ doc, err string }{
+		{`KEY`, "line 1: expected key = value"},
+		{`"a=b"`, "line 1: expected key = value"},
+		{`"open = "x"`, "expected key = value"},
+		{"\n[TABLE", "line 2: unclosed table"},
+		{`bad key = "x"`, "invalid bare key"},
+		{`= "x"`, "empty key"},
+		{`KEY = x`, "expected a string"},
+		{`KEY = "x`, "unclosed string"},
+		{`KEY = "x" y`, `unexpected "y" after string`},
+		{`KEY = "\q"`, "invalid string"},
+	}
+	for _, tt := range tests {
+		err := New("en").LoadTOML("en", []byte(tt.doc))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("LoadTOML(%q) = %v, want %q", tt.doc, err, tt.err)
+		}
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	c := New("en")
+	if err := c.LoadJSON("id", []byte(`{"NO_DATA":"Data tidak ditemukan","ITEMS":{"other":"{count} barang"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.T("id", "ITEMS", map[string]any{"count": 2}); got != "2 barang" {
+		t.Errorf("T = %q", got)
+	}
+	for _, doc := range []string{`[]`, `{"KEY":1}`, `{"KEY":{"one":1}}`} {
+		if err := c.LoadJSON("id", []byte(doc)); err == nil {
+			t.Errorf("LoadJSON(%s) = nil, want an error", doc)
+		}
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/en.json":    {Data: []byte(`{"HELLO":"Hello"}`)},
+		"locales/pt_BR.toml": {Data: []byte(`HELLO = "Olá"`)},
+		"locales/de.yaml":    {Data: []byte(`HELLO: Hallo`)},
+	}
+	c := New("en")
+	if err := c.LoadFS(fsys, "locales/*.json", "locales/*.toml"); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.T("pt-BR", "HELLO", nil); got != "Olá" {
+		t.Errorf("T(pt-BR) = %q", got)
+	}
+	if err := c.LoadFS(fsys, "locales/*.yaml"); err == nil || !strings.Contains(err.Error(), "unsupported file type") {
+		t.Errorf("LoadFS(yaml) = %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"math"
+	"strings"
+	"sync"
+)
+
+// PluralRule returns the plural category of n: "zero", "one", "two", "few",
+// "many" or "other"
+type PluralRule func(n float64) string
+
+var (
+	pluralMU sync.RWMutex
+	// built-in rules by base language, others use the English rule
+	pluralRules = map[string]PluralRule{
+		"en": oneIfOne,
+		"de": oneIfOne,
+		"nl": oneIfOne,
+		"es": oneIfOne,
+		"it": oneIfOne,
+		"sv": oneIfOne,
+		"fr": func(n float64) string {
+			if n >= 0 && n < 2 {
+				return "one"
+			}
+			return "other"
+		},
+		// no plural forms
+		"id": otherOnly,
+		"ms": otherOnly,
+		"ja": otherOnly,
+		"ko": otherOnly,
+		"zh": otherOnly,
+		"th": otherOnly,
+		"vi": otherOnly,
+	}
+)
+
+func oneIfOne(n float64) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func otherOnly(float64) string {
+	return "other"
+}
+
+// RegisterPluralRule sets the plural rule of a language, it replaces a
+// built-in rule with the same name
+//
+// Example:
+//
+//	i18n.RegisterPluralRule("ru", func(n float64) string { ... })
+func RegisterPluralRule(lang string, rule PluralRule) {
+	pluralMU.Lock()
+	pluralRules[normalize(lang)] = rule
+	pluralMU.Unlock()
+}
+
+// Plural returns the plural category of n in locale, the rule of the full
+// tag is tried before the one of its base language
+func Plural(locale string, n float64) string {
+	locale = normalize(locale)
+	pluralMU.RLock()
+	rule, ok := pluralRules[locale]
+	if !ok {
+		base, _, _ := strings.Cut(locale, "-")
+		rule, ok = pluralRules[base]
+	}
+	pluralMU.RUnlock()
+	if !ok {
+		rule = oneIfOne
+	}
+	return rule(math.Abs(n))
+}
@@ -0,0 +1,65 @@
+package i18n
+
+import "testing"
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      float64
+		want   string
+	}{
+		{"en", 1, "one"},
+		{"en", 0, "other"},
+		{"en", -1, "one"},
+		{"en", 1.5, "other"},
+		{"en-GB", 1, "one"},
+		{"fr", 0, "one"},
+		{"fr", 1.9, "one"},
+		{"fr", 2, "other"},
+		{"fr_CA", 0, "one"},
+		{"ja", 1, "other"},
+		{"id", 1, "other"},
+		// unknown languages use the English rule
+		{"xx", 1, "one"},
+		{"xx", 2, "other"},
+	}
+	for _, tt := range tests {
+		if got := Plural(tt.locale, tt.n); got != tt.want {
+			t.Errorf("Plural(%s, %v) = %s, want %s", tt.locale, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterPluralRule(t *testing.T) {
+	few := func(n float64) string {
+		if n >= 2 && n <= 4 {
+			return "few"
+		}
+		return oneIfOne(n)
+	}
+	RegisterPluralRule("cs", few)
+	RegisterPluralRule("EN-x-test", otherOnly)
+	defer func() {
+		pluralMU.Lock()
+		delete(pluralRules, "cs")
+		delete(pluralRules, "en-x-test")
+		pluralMU.Unlock()
+	}()
+
+	for _, tt := range []struct {
+		locale string
+		n      float64
+		want   string
+	}{
+		{"cs", 3, "few"},
+		{"cs-CZ", 1, "one"},
+		{"cs", 5, "other"},
+		// the full tag rule wins over its base language
+		{"en-x-test", 1, "other"},
+		{"en", 1, "one"},
+	} {
+		if got := Plural(tt.locale, tt.n); got != tt.want {
+			t.Errorf("Plural(%s, %v) = %s, want %s", tt.locale, tt.n, got, tt.want)
+		}
+	}
+}
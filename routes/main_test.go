@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"os"
+	"testing"
+
+	"github.com/he-end/simproute/goruntime"
+)
+
+// the tests run in ModeDev so the prod logger never writes logs/app.log in
+// the package directory, the prod behaviour is tested with WithMode
+func TestMain(m *testing.M) {
+	goruntime.SetMode(goruntime.ModeDev)
+	os.Exit(m.Run())
+}
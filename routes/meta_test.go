@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/he-end/simproute/routes/response"
+)
+
+func serveMeta(t *testing.T, r *Router, method, path string) (*httptest.ResponseRecorder, response.Meta) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	var body response.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%s %s body %s: %v", method, path, rec.Body, err)
+	}
+	return rec, body.Meta
+}
+
+func TestResponseMeta(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(time.Millisecond)
+		response.For(req).Success(w, "OK", nil)
+	})
+
+	// the router answers carry the same Meta as the handler ones
+	for _, tt := range []struct{ method, path string }{
+		{http.MethodGet, "/users"},
+		{http.MethodGet, "/missing"},
+		{http.MethodPost, "/users"},
+	} {
+		rec, meta := serveMeta(t, r, tt.method, tt.path)
+		header := rec.Header().Get("X-Set-Corelation-ID")
+		if header == "" || meta.RequestID != header {
+			t.Errorf("%s %s: meta.request_id %q, header %q", tt.method, tt.path, meta.RequestID, header)
+		}
+		if _, err := time.ParseDuration(meta.Latency); err != nil {
+			t.Errorf("%s %s: meta.latency %q: %v", tt.method, tt.path, meta.Latency, err)
+		}
+	}
+
+	_, meta := serveMeta(t, r, http.MethodGet, "/users")
+	if d, _ := time.ParseDuration(meta.Latency); d < time.Millisecond {
+		t.Errorf("meta.latency = %s, want at least the handler time", meta.Latency)
+	}
+}
+
+func TestResponseMetaMiddlewares(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	r.Use(response.Version("2024-06-01"))
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := response.WithMeta(req.Context(), func(m *response.Meta) {
+				m.Extra = map[string]any{"region": "eu"}
+				// set by the outer middleware first
+				m.Version += "+eu"
+			})
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	})
+	ok := func(w http.ResponseWriter, req *http.Request) { response.For(req).Success(w, "OK", nil) }
+	r.Get("/v1/users", ok)
+	r.With(response.Deprecated("use /v2/users")).Get("/v0/users", ok)
+
+	rec, meta := serveMeta(t, r, http.MethodGet, "/v1/users")
+	if meta.Version != "2024-06-01+eu" || meta.Extra["region"] != "eu" || meta.Deprecation != "" {
+		t.Errorf("/v1/users meta = %+v", meta)
+	}
+	if rec.Header().Get("Deprecation") != "" {
+		t.Error("/v1/users has a Deprecation header")
+	}
+
+	rec, meta = serveMeta(t, r, http.MethodGet, "/v0/users")
+	if meta.Deprecation != "use /v2/users" || rec.Header().Get("Deprecation") != "true" {
+		t.Errorf("/v0/users meta.deprecation %q, header %q", meta.Deprecation, rec.Header().Get("Deprecation"))
+	}
+	if meta.RequestID != rec.Header().Get("X-Set-Corelation-ID") {
+		t.Errorf("/v0/users meta.request_id %q", meta.RequestID)
+	}
+}
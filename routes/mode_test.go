@@ -0,0 +1,132 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/he-end/simproute/goruntime"
+	"github.com/he-end/simproute/routes/response"
+)
+
+func TestModeDefault(t *testing.T) {
+	t.Setenv(goruntime.EnvMode, "")
+	if m := goruntime.ModeFromEnv(); m != goruntime.ModeProd {
+		t.Errorf("ModeFromEnv without %s = %s, want prod", goruntime.EnvMode, m)
+	}
+	for env, want := range map[string]goruntime.Mode{
+		"dev":         goruntime.ModeDev,
+		"Development": goruntime.ModeDev,
+		"production":  goruntime.ModeProd,
+		"staging":     goruntime.ModeProd,
+	} {
+		t.Setenv(goruntime.EnvMode, env)
+		if m := goruntime.ModeFromEnv(); m != want {
+			t.Errorf("ModeFromEnv with %q = %s, want %s", env, m, want)
+		}
+	}
+}
+
+// WithMode only changes the router, New without it takes the process mode
+func TestWithModeIsLocal(t *testing.T) {
+	before := goruntime.CurrentMode()
+	r := New(WithMode(goruntime.ModeProd))
+	if r.Mode != goruntime.ModeProd {
+		t.Fatalf("Mode = %s", r.Mode)
+	}
+	if goruntime.CurrentMode() != before {
+		t.Fatalf("WithMode changed the process mode to %s", goruntime.CurrentMode())
+	}
+	if r := New(); r.Mode != before {
+		t.Fatalf("New() Mode = %s, want the process mode %s", r.Mode, before)
+	}
+}
+
+func errorBody(t *testing.T, rec *httptest.ResponseRecorder) response.Response {
+	t.Helper()
+	var body response.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error == nil {
+		t.Fatalf("body %s: %v", rec.Body, err)
+	}
+	return body
+}
+
+func TestModeRedaction(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		redacted bool
+	}{
+		{"prod", []Option{WithMode(goruntime.ModeProd), WithDebugErrors()}, true},
+		{"dev", []Option{WithMode(goruntime.ModeDev)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(tt.opts...)
+			r.AccessLog = false
+			r.Get("/fail", func(w http.ResponseWriter, req *http.Request) {
+				response.For(req).ErrorDetail(w, http.StatusBadGateway, "upstream down", response.ErrorInfo{
+					Code:       response.ErrCodeInternalError,
+					Details:    "dial tcp 10.0.0.7:5432: connection refused",
+					Extensions: map[string]any{"host": "10.0.0.7"},
+				})
+			})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+			body := errorBody(t, rec)
+			if rec.Code != http.StatusBadGateway {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			redacted := body.Error.Details == "An unexpected error occurred" && body.Error.Extensions == nil &&
+				body.Message == "Internal server error"
+			if redacted != tt.redacted {
+				t.Errorf("redacted = %v, want %v: %s", redacted, tt.redacted, rec.Body)
+			}
+		})
+	}
+}
+
+func TestModeRecoveredPanic(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		debug bool
+	}{
+		{"prod with debug errors", []Option{WithMode(goruntime.ModeProd), WithDebugErrors()}, false},
+		{"dev", []Option{WithMode(goruntime.ModeDev)}, false},
+		{"dev with debug errors", []Option{WithMode(goruntime.ModeDev), WithDebugErrors()}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(tt.opts...)
+			r.AccessLog = false
+			r.Get("/panic", func(w http.ResponseWriter, req *http.Request) {
+				panic(fmt.Errorf("load user: %w", errors.New("db closed")))
+			})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			body := errorBody(t, rec)
+			_, hasStack := body.Error.Extensions["stack"]
+			if tt.debug {
+				if body.Error.Details != "load user: db closed" || !hasStack {
+					t.Errorf("debug 500 = %s", rec.Body)
+				}
+				if chain, _ := body.Error.Extensions["errors"].([]any); len(chain) != 2 {
+					t.Errorf("error chain = %v", body.Error.Extensions["errors"])
+				}
+				return
+			}
+			if body.Error.Details != "An unexpected error occurred" || hasStack {
+				t.Errorf("redacted 500 = %s", rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package routes
+
+// Operation is the API documentation attached to a route, read by the
+// openapi package through Walk
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// sample value of the request body type, nil for no body
+	Request any
+	// sample value of the response type per status, nil for no body
+	Responses map[int]any
+	// security requirements, scheme name -> scopes
+	Security   []map[string][]string
+	Deprecated bool
+	// left out of generated documents
+	Hidden bool
+}
+
+// Summary sets the short description of the route
+func (rt *Route) Summary(summary string) *Route {
+	return rt.update(func(op *Operation) { op.Summary = summary })
+}
+
+// Description sets the long description of the route
+func (rt *Route) Description(description string) *Route {
+	return rt.update(func(op *Operation) { op.Description = description })
+}
+
+// Tags adds tags grouping the route in the documentation
+func (rt *Route) Tags(tags ...string) *Route {
+	return rt.update(func(op *Operation) { op.Tags = append(op.Tags, tags...) })
+}
+
+// Body sets the request body type, v is a sample value (e.g. CreateUser{})
+func (rt *Route) Body(v any) *Route {
+	return rt.update(func(op *Operation) { op.Request = v })
+}
+
+// Response sets the response type for status, v is a sample value,
+// nil documents a response without body
+//
+// Example:
+//
+//	r.Get("/users/{id:int}", GetUser).Response(200, User{}).Response(404, nil)
+func (rt *Route) Response(status int, v any) *Route {
+	return rt.update(func(op *Operation) {
+		if op.Responses == nil {
+			op.Responses = make(map[int]any)
+		}
+		op.Responses[status] = v
+	})
+}
+
+// Security adds a security requirement, scheme is a name of the
+// security schemes of the document
+func (rt *Route) Security(scheme string, scopes ...string) *Route {
+	return rt.update(func(op *Operation) {
+		op.Security = append(op.Security, map[string][]string{scheme: append([]string{}, scopes...)})
+	})
+}
+
+// Deprecated marks the route deprecated in the documentation
+func (rt *Route) Deprecated() *Route {
+	return rt.update(func(op *Operation) { op.Deprecated = true })
+}
+
+// Hidden leaves the route out of the documentation
+func (rt *Route) Hidden() *Route {
+	return rt.update(func(op *Operation) { op.Hidden = true })
+}
+
+// update changes the Operation of the route under the lock of its Router
+func (rt *Route) update(fn func(op *Operation)) *Route {
+	if owner := rt.rt.owner; owner != nil {
+		owner.MU.Lock()
+		defer owner.MU.Unlock()
+	}
+	fn(&rt.rt.op)
+	return rt
+}
@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/he-end/simproute/goruntime"
+	"github.com/he-end/simproute/tracing"
+)
+
+// Option configures a Router created by New
+type Option func(*Router)
+
+// WithNotFound sets the handler for requests without a matching route
+//
+// Example:
+//
+//	r := routes.New(routes.WithNotFound(http.HandlerFunc(NotFoundPage)))
+func WithNotFound(h http.Handler) Option {
+	return func(r *Router) { r.NotFound = h }
+}
+
+// WithMethodNotAllowed sets the handler for a path registered under other
+// methods, the Allow header is set before it runs
+func WithMethodNotAllowed(h http.Handler) Option {
+	return func(r *Router) { r.MethodNotAllowed = h }
+}
+
+// WithPanicHandler sets the func writing the response after a recovered
+// panic, it receives the recovered value and the stack of the panic
+func WithPanicHandler(fn func(w http.ResponseWriter, r *http.Request, recovered any, stack []byte)) Option {
+	return func(r *Router) { r.PanicHandler = fn }
+}
+
+// WithConflictPolicy sets what Handle does with a route conflict
+//
+// Example:
+//
+//	r := routes.New(routes.WithConflictPolicy(routes.ConflictPanic))
+func WithConflictPolicy(p ConflictPolicy) Option {
+	return func(r *Router) { r.Conflicts = p }
+}
+
+// WithCorelation replaces the default correlation config
+func WithCorelation(cfg CorelationConfig) Option {
+	return func(r *Router) { r.Corelation = cfg }
+}
+
+// WithTracer enables tracing with t
+func WithTracer(t *tracing.Tracer) Option {
+	return func(r *Router) { r.Tracer = t }
+}
+
+// WithMode sets the Mode of the router, it overrides SIMPROUTE_MODE for the
+// responses and the recovery of its requests. The process mode and the
+// logger are left alone, change them with goruntime.SetMode.
+//
+// Example:
+//
+//	r := routes.New(routes.WithMode(goruntime.ModeDev))
+func WithMode(m goruntime.Mode) Option {
+	return func(r *Router) { r.Mode = m }
+}
+
+// WithDebugErrors shows the panic value, error chain and stack trace in the
+// 500 of a recovered panic, only when the router Mode is ModeDev
+func WithDebugErrors() Option {
+	return func(r *Router) { r.DebugErrors = true }
+}
@@ -0,0 +1,134 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/he-end/simproute/cors"
+	"github.com/he-end/simproute/goruntime"
+)
+
+// without a cors middleware OPTIONS only gets Allow, the CORS headers the
+// router used to hardcode are gone
+func TestOptionsWithoutCORS(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	r.Get("/users/:id", func(w http.ResponseWriter, req *http.Request) {})
+	r.PUT("/users/:id", func(w http.ResponseWriter, req *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodOptions, "/users/1", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", rec.Code)
+	}
+	if got := rec.Header().Get("Allow"); got != "GET, HEAD, OPTIONS, PUT" {
+		t.Errorf("Allow = %q", got)
+	}
+	for _, name := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Methods", "Access-Control-Allow-Headers"} {
+		if v := rec.Header().Get(name); v != "" {
+			t.Errorf("%s = %q, want none", name, v)
+		}
+	}
+}
+
+// the preflight of a group route runs through the cors middleware of the group
+func TestOptionsGroupCORS(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	r.Get("/public", func(w http.ResponseWriter, req *http.Request) {})
+	r.Group("/api", func(gr *Router) {
+		gr.Use(cors.Middleware(cors.Options{AllowedOrigins: []string{"https://app.example.com"}}))
+		gr.POST("/items", func(w http.ResponseWriter, req *http.Request) {})
+	})
+
+	preflight := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := preflight("/api/items")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("/api/items Allow-Origin = %q", got)
+	}
+	rec = preflight("/public")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("/public outside the group got Allow-Origin %q", got)
+	}
+}
+
+// the custom 404, 405 and panic handlers run inside the root middleware,
+// the panic handler gets the recovered value and the stack
+func TestCustomHandlers(t *testing.T) {
+	var recovered any
+	var stack []byte
+	r := New(
+		WithMode(goruntime.ModeProd),
+		WithDebugErrors(),
+		WithNotFound(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte("custom 404 " + w.Header().Get("X-Mw")))
+		})),
+		WithMethodNotAllowed(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte("custom 405 " + w.Header().Get("X-Mw") + " " + w.Header().Get("Allow")))
+		})),
+		WithPanicHandler(func(w http.ResponseWriter, req *http.Request, rec any, st []byte) {
+			recovered, stack = rec, st
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("custom 500 " + w.Header().Get("X-Mw")))
+		}),
+	)
+	r.AccessLog = false
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Mw", "root")
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Get("/users", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/panic", func(w http.ResponseWriter, req *http.Request) { panic("boom") })
+
+	tests := []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{http.MethodGet, "/missing", http.StatusTeapot, "custom 404 root"},
+		{http.MethodPost, "/users", http.StatusConflict, "custom 405 root GET, HEAD, OPTIONS"},
+		{http.MethodGet, "/panic", http.StatusServiceUnavailable, "custom 500 root"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.status || rec.Body.String() != tt.body {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, rec.Code, rec.Body, tt.status, tt.body)
+		}
+	}
+	if recovered != "boom" || !strings.Contains(string(stack), "panic") {
+		t.Errorf("panic handler got %v and stack %q", recovered, stack)
+	}
+}
+
+// without a panic handler a prod router never shows the panic, even with
+// WithDebugErrors
+func TestRecoveredPanicHiddenInProd(t *testing.T) {
+	r := New(WithMode(goruntime.ModeProd), WithDebugErrors())
+	r.AccessLog = false
+	r.Get("/panic", func(w http.ResponseWriter, req *http.Request) { panic("secret dsn") })
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "secret dsn") || strings.Contains(rec.Body.String(), "stack") {
+		t.Errorf("prod 500 = %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,258 @@
+package response
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/he-end/simproute/internal/jsonvalue"
+)
+
+// The MessagePack, CBOR and XML encoders write the JSON form of the value,
+// so json tags and custom MarshalJSON apply to every format.
+
+func encodeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	// keep the & of page links readable
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// encodeMsgpack writes v as MessagePack
+func encodeMsgpack(w io.Writer, v any) error {
+	g, err := jsonvalue.Of(v)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	writeMsgpack(&b, g)
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+func writeMsgpack(b *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteByte(0xc0)
+	case bool:
+		if v {
+			b.WriteByte(0xc3)
+		} else {
+			b.WriteByte(0xc2)
+		}
+	case int64:
+		switch {
+		case v >= 0:
+			writeMsgpackUint(b, uint64(v))
+		case v >= -32:
+			b.WriteByte(byte(v))
+		case v >= math.MinInt8:
+			b.Write([]byte{0xd0, byte(v)})
+		case v >= math.MinInt16:
+			b.WriteByte(0xd1)
+			b.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+		case v >= math.MinInt32:
+			b.WriteByte(0xd2)
+			b.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+		default:
+			b.WriteByte(0xd3)
+			b.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+		}
+	case json.Number:
+		writeMsgpack(b, jsonvalue.Number(v))
+	case uint64:
+		writeMsgpackUint(b, v)
+	case float64:
+		b.WriteByte(0xcb)
+		b.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+	case string:
+		writeMsgpackHead(b, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		b.WriteString(v)
+	case []any:
+		writeMsgpackHead(b, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			writeMsgpack(b, item)
+		}
+	case []jsonvalue.Field:
+		writeMsgpackHead(b, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, f := range v {
+			writeMsgpack(b, f.Key)
+			writeMsgpack(b, f.Value)
+		}
+	}
+}
+
+func writeMsgpackUint(b *bytes.Buffer, v uint64) {
+	switch {
+	case v <= math.MaxInt8:
+		b.WriteByte(byte(v))
+	case v <= math.MaxUint8:
+		b.Write([]byte{0xcc, byte(v)})
+	case v <= math.MaxUint16:
+		b.WriteByte(0xcd)
+		b.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+	case v <= math.MaxUint32:
+		b.WriteByte(0xce)
+		b.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+	default:
+		b.WriteByte(0xcf)
+		b.Write(binary.BigEndian.AppendUint64(nil, v))
+	}
+}
+
+// writeMsgpackHead writes the header of a string, array or map of n items:
+// the fix form below fixMax, then the 8 (when the format has one), 16 and
+// 32 bit forms
+func writeMsgpackHead(b *bytes.Buffer, n int, fix byte, fixMax int, c8, c16, c32 byte) {
+	switch {
+	case n < fixMax:
+		b.WriteByte(fix | byte(n))
+	case c8 != 0 && n <= math.MaxUint8:
+		b.Write([]byte{c8, byte(n)})
+	case n <= math.MaxUint16:
+		b.WriteByte(c16)
+		b.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		b.WriteByte(c32)
+		b.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// encodeCBOR writes v as CBOR (RFC 8949)
+func encodeCBOR(w io.Writer, v any) error {
+	g, err := jsonvalue.Of(v)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	writeCBOR(&b, g)
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+func writeCBOR(b *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteByte(0xf6)
+	case bool:
+		if v {
+			b.WriteByte(0xf5)
+		} else {
+			b.WriteByte(0xf4)
+		}
+	case int64:
+		if v >= 0 {
+			writeCBORHead(b, 0, uint64(v))
+		} else {
+			writeCBORHead(b, 1, uint64(-1-v))
+		}
+	case json.Number:
+		writeCBOR(b, jsonvalue.Number(v))
+	case uint64:
+		writeCBORHead(b, 0, v)
+	case float64:
+		b.WriteByte(0xfb)
+		b.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+	case string:
+		writeCBORHead(b, 3, uint64(len(v)))
+		b.WriteString(v)
+	case []any:
+		writeCBORHead(b, 4, uint64(len(v)))
+		for _, item := range v {
+			writeCBOR(b, item)
+		}
+	case []jsonvalue.Field:
+		writeCBORHead(b, 5, uint64(len(v)))
+		for _, f := range v {
+			writeCBOR(b, f.Key)
+			writeCBOR(b, f.Value)
+		}
+	}
+}
+
+func writeCBORHead(b *bytes.Buffer, major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		b.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		b.Write([]byte{major | 24, byte(n)})
+	case n <= math.MaxUint16:
+		b.WriteByte(major | 25)
+		b.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32:
+		b.WriteByte(major | 26)
+		b.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		b.WriteByte(major | 27)
+		b.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
+
+// EncodeXML writes v as XML under a <response> element, object members
+// become elements and array items <item> elements. It is not registered by
+// default, see RegisterEncoder.
+func EncodeXML(w io.Writer, v any) error {
+	g, err := jsonvalue.Of(v)
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	if err := writeXML(enc, "response", g); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func writeXML(enc *xml.Encoder, name string, v any) error {
+	start := xml.StartElement{Name: xml.Name{Local: xmlName(name)}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case []jsonvalue.Field:
+		for _, f := range v {
+			if err := writeXML(enc, f.Key, f.Value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := writeXML(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		text, _ := json.Marshal(v)
+		if s, ok := v.(string); ok {
+			text = []byte(s)
+		}
+		if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlName replaces the characters not allowed in an element name
+func xmlName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" || !(name[0] == '_' || (name[0]|0x20) >= 'a' && (name[0]|0x20) <= 'z') {
+		name = "_" + name
+	}
+	return name
+}
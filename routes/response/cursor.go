@@ -0,0 +1,81 @@
+package response
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidCursor is returned by Cursors.Decode for a cursor that was not
+// made by Encode with the same secret, it answers 400 through the error registry
+var ErrInvalidCursor = errors.New("response: invalid cursor")
+
+func init() {
+	RegisterError(ErrInvalidCursor, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid cursor")
+}
+
+// Cursors makes tamper-evident cursors signed with HMAC-SHA256, so clients
+// cannot craft or change them. They are not opaque: the payload is only
+// JSON encoded in base64 and anyone can read it, keep secrets out of it.
+//
+// Example:
+//
+//	var cursors = response.NewCursors([]byte(os.Getenv("CURSOR_SECRET")))
+//
+//	next, err := cursors.Encode(LastSeen{ID: users[len(users)-1].ID})
+//	...
+//	var after LastSeen
+//	err := cursors.Decode(cursor, &after)
+type Cursors struct {
+	secret []byte
+}
+
+// NewCursors returns the cursors signed with secret, it panics when secret
+// is empty since anyone could then sign a cursor
+func NewCursors(secret []byte) *Cursors {
+	if len(secret) == 0 {
+		panic("response: NewCursors called with an empty secret")
+	}
+	return &Cursors{secret: append([]byte(nil), secret...)}
+}
+
+// Encode returns the cursor holding v (as JSON)
+func (c *Cursors) Encode(v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode checks the signature of cursor and decodes its value into v
+func (c *Cursors) Decode(cursor string, v any) error {
+	enc := base64.RawURLEncoding
+	p, s, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return ErrInvalidCursor
+	}
+	payload, err := enc.DecodeString(p)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(s)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+func (c *Cursors) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
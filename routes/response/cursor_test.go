@@ -0,0 +1,66 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type lastSeen struct {
+	ID int `json:"id"`
+}
+
+func TestCursors(t *testing.T) {
+	c := NewCursors([]byte("secret"))
+	cursor, err := c.Encode(lastSeen{ID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got lastSeen
+	if err := c.Decode(cursor, &got); err != nil || got.ID != 42 {
+		t.Fatalf("Decode = %v, %v", got, err)
+	}
+
+	payload, sig, _ := strings.Cut(cursor, ".")
+	other, _ := NewCursors([]byte("other")).Encode(lastSeen{ID: 42})
+	forged, _ := c.Encode(lastSeen{ID: 1})
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+	for name, bad := range map[string]string{
+		"other secret":    other,
+		"changed payload": forgedPayload + "." + sig,
+		"no signature":    payload,
+		"bad base64":      payload + ".!!",
+		"empty":           "",
+	} {
+		if err := c.Decode(bad, &got); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: Decode err = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func TestNewCursorsEmptySecret(t *testing.T) {
+	for _, secret := range [][]byte{nil, {}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewCursors(%q) did not panic", secret)
+				}
+			}()
+			NewCursors(secret)
+		}()
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users?cursor=abc&limit=500", nil)
+	cursor, limit := ParseCursor(req, 20, 100)
+	if cursor != "abc" || limit != 100 {
+		t.Errorf("ParseCursor = %q, %d", cursor, limit)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/users?limit=-1", nil)
+	if cursor, limit := ParseCursor(req, 20, 100); cursor != "" || limit != 20 {
+		t.Errorf("ParseCursor = %q, %d", cursor, limit)
+	}
+}
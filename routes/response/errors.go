@@ -0,0 +1,111 @@
+package response
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// HTTPError is an error carrying the response it should produce
+//
+// Example:
+//
+//	return response.NewError(http.StatusConflict, ErrCodeEmailRegistered, MsgEmailRegistered)
+type HTTPError struct {
+	Status  int
+	Code    string
+	Message string
+	Details string
+	// extra members of the error, see ErrorInfo.Extensions
+	Extensions map[string]any
+	// cause, logged and matched by errors.Is / errors.As
+	Err error
+}
+
+// NewError returns an HTTPError for status with the response code and message
+func NewError(status int, code, message string) *HTTPError {
+	return &HTTPError{Status: status, Code: code, Message: message}
+}
+
+// WithDetails returns a copy of e with details
+func (e *HTTPError) WithDetails(details string) *HTTPError {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// WithExtension returns a copy of e with the extra member key
+func (e *HTTPError) WithExtension(key string, value any) *HTTPError {
+	c := *e
+	c.Extensions = make(map[string]any, len(e.Extensions)+1)
+	for k, v := range e.Extensions {
+		c.Extensions[k] = v
+	}
+	c.Extensions[key] = value
+	return &c
+}
+
+// Wrap returns a copy of e caused by err
+func (e *HTTPError) Wrap(err error) *HTTPError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *HTTPError) Unwrap() error { return e.Err }
+
+var (
+	errorsMU sync.RWMutex
+	// sentinel errors mapped to responses, checked with errors.Is from the
+	// last registered one
+	registry = []struct {
+		target error
+		resp   *HTTPError
+	}{
+		{sql.ErrNoRows, NewError(http.StatusNotFound, ErrCodeNoDataFound, MsgNoFound)},
+		{context.DeadlineExceeded, NewError(http.StatusGatewayTimeout, ErrCodeInternalError, "Request timed out")},
+	}
+)
+
+// RegisterError maps errors matching target (errors.Is) to a response.
+// Later registrations are checked first, so registering a target again
+// replaces its response. target may be of any error type, comparable or not.
+//
+// Example:
+//
+//	response.RegisterError(repo.ErrDuplicate, http.StatusConflict, response.ErrCodeAlreadyExist, "Already exists")
+func RegisterError(target error, status int, code, message string) {
+	errorsMU.Lock()
+	defer errorsMU.Unlock()
+	registry = append(registry, struct {
+		target error
+		resp   *HTTPError
+	}{target, NewError(status, code, message)})
+}
+
+// ErrorOf returns the response for err: the HTTPError in its chain, or the
+// registered response of a matching sentinel. nil when err is unknown.
+func ErrorOf(err error) *HTTPError {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he
+	}
+
+	errorsMU.RLock()
+	defer errorsMU.RUnlock()
+	for i := len(registry) - 1; i >= 0; i-- {
+		if errors.Is(err, registry[i].target) {
+			return registry[i].resp.Wrap(err)
+		}
+	}
+	return nil
+}
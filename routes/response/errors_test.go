@@ -0,0 +1,65 @@
+package response
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// a struct error with a slice field, == on two of them panics
+type multiError struct {
+	Fields []string
+}
+
+func (e multiError) Error() string { return fmt.Sprint("invalid ", e.Fields) }
+
+func TestErrorOf(t *testing.T) {
+	if ErrorOf(errors.New("unknown")) != nil {
+		t.Error("unknown error mapped to a response")
+	}
+
+	he := ErrorOf(fmt.Errorf("load: %w", sql.ErrNoRows))
+	if he == nil || he.Status != http.StatusNotFound || he.Code != ErrCodeNoDataFound {
+		t.Errorf("sql.ErrNoRows = %+v", he)
+	}
+	if he := ErrorOf(context.DeadlineExceeded); he == nil || he.Status != http.StatusGatewayTimeout {
+		t.Errorf("context.DeadlineExceeded = %+v", he)
+	}
+
+	// an HTTPError in the chain wins over the registry
+	own := NewError(http.StatusConflict, ErrCodeAlreadyExist, "Project name taken").Wrap(sql.ErrNoRows)
+	if got := ErrorOf(fmt.Errorf("create: %w", own)); got != own {
+		t.Errorf("HTTPError in chain = %+v", got)
+	}
+}
+
+func TestRegisterErrorNotComparable(t *testing.T) {
+	target := multiError{Fields: []string{"a"}}
+
+	// registering a non comparable target twice must not panic
+	RegisterError(target, http.StatusBadRequest, ErrCodeInvalidRequest, "first")
+	RegisterError(target, http.StatusUnprocessableEntity, ErrCodeValidationError, "second")
+
+	// errors.Is only matches a non comparable target through an Is method,
+	// the lookup itself must not panic
+	if he := ErrorOf(multiError{Fields: []string{"a"}}); he != nil {
+		t.Errorf("non comparable value matched %+v", he)
+	}
+}
+
+func TestRegisterErrorReplaces(t *testing.T) {
+	errTaken := errors.New("taken")
+	RegisterError(errTaken, http.StatusBadRequest, ErrCodeInvalidRequest, "first")
+	RegisterError(errTaken, http.StatusConflict, ErrCodeAlreadyExist, "second")
+
+	he := ErrorOf(fmt.Errorf("create: %w", errTaken))
+	if he == nil || he.Status != http.StatusConflict || he.Message != "second" {
+		t.Fatalf("ErrorOf = %+v, want the last registration", he)
+	}
+	if !errors.Is(he, errTaken) {
+		t.Error("response does not wrap the cause")
+	}
+}
@@ -0,0 +1,143 @@
+package response
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/he-end/simproute/routes/i18n"
+)
+
+var (
+	catalogMU sync.RWMutex
+	catalog   = i18n.Default()
+)
+
+// SetCatalog sets the catalog localizing the error messages of every
+// ResponseHandler without its own, nil turns the localization off.
+// The default is i18n.Default() (English, Indonesian and Japanese).
+//
+// Example:
+//
+//	c := i18n.New("en")
+//	c.LoadFS(locales, "locales/*.toml")
+//	response.SetCatalog(c)
+func SetCatalog(c *i18n.Catalog) {
+	catalogMU.Lock()
+	catalog = c
+	catalogMU.Unlock()
+}
+
+func (rh *ResponseHandler) catalog() *i18n.Catalog {
+	if rh.Catalog != nil {
+		return rh.Catalog
+	}
+	catalogMU.RLock()
+	defer catalogMU.RUnlock()
+	return catalog
+}
+
+// defaultMessages are the messages the package sends with a code that
+// differ from the text of the code in the catalog default locale
+var defaultMessages = map[string][]string{
+	ErrCodeURLInvalid:       {MsgUrlInvalid},
+	ErrCodeEmailRegistered:  {MsgEmailRegistered},
+	ErrCodeUserAlreadyUse:   {MsgUserAlreadyUse},
+	ErrCodePasswordMismatch: {MsgPasswordMismatch},
+	ErrCodeNoFound:          {MsgNoFound},
+	ErrCodeNoDataFound:      {MsgNoFound},
+	ErrCodeDuplicatKey:      {MsgDuplicatKey},
+}
+
+// localize translates an error response in the language accepted by the
+// request. The message is replaced only when it is a default one for its
+// code (the catalog text of the default locale or the Msg* constant), a
+// message written by the handler is kept. The generic message of a
+// redacted 5xx uses the INTERNAL_ERROR text. The error extensions are the
+// params of the message and "count" defaults to the number of fields.
+// Field messages with a MessageKey are translated too.
+// Responses without request or Accept-Language are left alone.
+func (rh *ResponseHandler) localize(w http.ResponseWriter, resp *Response) {
+	if rh.request == nil || resp.Error == nil {
+		return
+	}
+	accept := rh.request.Header.Get("Accept-Language")
+	c := rh.catalog()
+	if accept == "" || c == nil {
+		return
+	}
+	w.Header().Add("Vary", "Accept-Language")
+	chain := c.Match(accept)
+
+	params := make(map[string]any, len(resp.Error.Extensions)+1)
+	for k, v := range resp.Error.Extensions {
+		params[k] = v
+	}
+	if _, ok := params["count"]; !ok {
+		params["count"] = len(resp.Error.Fields)
+	}
+	language := ""
+	if key, ok := messageKey(c, resp, params); ok {
+		if text, locale, ok := c.Lookup(chain, key, params); ok {
+			resp.Message, language = text, locale
+		}
+	}
+
+	// copy, the fields may be the ones of a validate.Errors
+	fields := make([]FieldError, len(resp.Error.Fields))
+	copy(fields, resp.Error.Fields)
+	for i, fe := range fields {
+		if fe.MessageKey == "" {
+			continue
+		}
+		text, locale, ok := c.Lookup(chain, fe.MessageKey, map[string]any{"field": fe.Field, "param": fe.Param})
+		if !ok {
+			continue
+		}
+		fields[i].Message = text
+		if language == "" {
+			language = locale
+		}
+	}
+	if len(fields) > 0 {
+		resp.Error.Fields = fields
+	}
+	if language != "" {
+		w.Header().Set("Content-Language", language)
+	}
+}
+
+// messageKey returns the catalog key of the message of resp, ok is false
+// when the message is not a default one of its code
+func messageKey(c *i18n.Catalog, resp *Response, params map[string]any) (key string, ok bool) {
+	code := resp.Error.Code
+	switch {
+	case resp.Message == "":
+		return code, true
+	case resp.Message == MsgInternalError:
+		return ErrCodeInternalError, true
+	case slices.Contains(defaultMessages[code], resp.Message):
+		return code, true
+	}
+	text, _, ok := c.Lookup([]string{c.DefaultLocale()}, code, params)
+	return code, ok && text == resp.Message
+}
+
+// T returns the message of key in the language accepted by the request,
+// key itself when the catalog has none
+//
+// Example:
+//
+//	rh := response.For(r)
+//	rh.Success(w, rh.T("USER_UPDATED", nil), user)
+func (rh *ResponseHandler) T(key string, params map[string]any) string {
+	c := rh.catalog()
+	if c == nil {
+		return key
+	}
+	accept := ""
+	if rh.request != nil {
+		accept = rh.request.Header.Get("Accept-Language")
+	}
+	return c.T(accept, key, params)
+}
@@ -0,0 +1,94 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func localized(t *testing.T, lang string, write func(rh *ResponseHandler, w http.ResponseWriter)) (*httptest.ResponseRecorder, Response) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if lang != "" {
+		req.Header.Set("Accept-Language", lang)
+	}
+	rec := httptest.NewRecorder()
+	write(For(req), rec)
+	var body Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %s: %v", rec.Body, err)
+	}
+	return rec, body
+}
+
+func TestLocalizeMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		write    func(rh *ResponseHandler, w http.ResponseWriter)
+		want     string
+		language string
+	}{
+		{"catalog text", "ja", func(rh *ResponseHandler, w http.ResponseWriter) {
+			rh.Fail(w, "Validation failed", ErrCodeValidationError, "")
+		}, "入力内容の検証に失敗しました", "ja"},
+		{"Msg constant", "id-ID, en;q=0.5", func(rh *ResponseHandler, w http.ResponseWriter) {
+			rh.Fail(w, MsgNoFound, ErrCodeNoDataFound, "")
+		}, "Data tidak ditemukan", "id"},
+		{"empty message", "id", func(rh *ResponseHandler, w http.ResponseWriter) {
+			rh.Fail(w, "", ErrCodeInvalidToken, "")
+		}, "Token tidak valid", "id"},
+		// a message written by the handler is kept
+		{"handler message", "ja", func(rh *ResponseHandler, w http.ResponseWriter) {
+			rh.Fail(w, "User 42 is banned until Monday", ErrCodeInvalidRequest, "")
+		}, "User 42 is banned until Monday", ""},
+		{"no Accept-Language", "", func(rh *ResponseHandler, w http.ResponseWriter) {
+			rh.Fail(w, MsgNoFound, ErrCodeNoDataFound, "")
+		}, MsgNoFound, ""},
+		{"unknown language", "fr", func(rh *ResponseHandler, w http.ResponseWriter) {
+			rh.Fail(w, MsgNoFound, ErrCodeNoDataFound, "")
+		}, "Resource not found", "en"},
+		// the generic message of a redacted 5xx whatever the code
+		{"redacted 5xx", "ja", func(rh *ResponseHandler, w http.ResponseWriter) {
+			rh.Dev = false
+			rh.Error(w, "pq: connection refused", ErrCodeDatabaseError, "dial tcp", http.StatusInternalServerError)
+		}, "サーバー内部でエラーが発生しました", "ja"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, body := localized(t, tt.lang, tt.write)
+			if body.Message != tt.want {
+				t.Errorf("message = %q, want %q", body.Message, tt.want)
+			}
+			if got := rec.Header().Get("Content-Language"); got != tt.language {
+				t.Errorf("Content-Language = %q, want %q", got, tt.language)
+			}
+		})
+	}
+}
+
+func TestLocalizeFields(t *testing.T) {
+	fields := []FieldError{
+		{Field: "name", Rule: "min", Param: "3", Message: "name must be at least 3 characters", MessageKey: "validate.min.string"},
+		{Field: "plan", Rule: "slug", Message: "plan must be a slug"},
+	}
+	rec, body := localized(t, "ja", func(rh *ResponseHandler, w http.ResponseWriter) {
+		rh.Fail(w, "Check the form", ErrCodeValidationError, "", fields...)
+	})
+
+	if body.Message != "Check the form" {
+		t.Errorf("message = %q, want the handler one", body.Message)
+	}
+	if rec.Header().Get("Content-Language") != "ja" {
+		t.Errorf("Content-Language = %q", rec.Header().Get("Content-Language"))
+	}
+	got := body.Error.Fields
+	if len(got) != 2 || got[0].Message != "nameは3文字以上である必要があります" || got[1].Message != "plan must be a slug" {
+		t.Errorf("fields = %+v", got)
+	}
+	// the fields of the caller are not changed
+	if fields[0].Message != "name must be at least 3 characters" {
+		t.Errorf("caller field changed to %q", fields[0].Message)
+	}
+}
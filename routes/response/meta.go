@@ -0,0 +1,101 @@
+package response
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/he-end/simproute/goruntime"
+)
+
+type metaKey struct{}
+
+// metaState is what the request context carries for the Meta of its responses
+type metaState struct {
+	start time.Time
+	fns   []func(*Meta)
+}
+
+func metaFrom(ctx context.Context) *metaState {
+	st, _ := ctx.Value(metaKey{}).(*metaState)
+	return st
+}
+
+// WithStart returns a copy of ctx remembering when the request started,
+// responses written for it report the elapsed time in Meta.Latency.
+// The router calls it for every request it serves.
+func WithStart(ctx context.Context, start time.Time) context.Context {
+	st := &metaState{start: start}
+	if old := metaFrom(ctx); old != nil {
+		st.fns = old.fns
+	}
+	return context.WithValue(ctx, metaKey{}, st)
+}
+
+// WithMeta returns a copy of ctx whose responses are passed to fn before
+// they are written, fn may set any Meta field. Functions added by outer
+// middlewares run first.
+//
+// Example:
+//
+//	ctx := response.WithMeta(r.Context(), func(m *response.Meta) {
+//		m.Version = "2024-06-01"
+//	})
+//	next.ServeHTTP(w, r.WithContext(ctx))
+func WithMeta(ctx context.Context, fn func(m *Meta)) context.Context {
+	st := &metaState{}
+	if old := metaFrom(ctx); old != nil {
+		st.start = old.start
+		// copy so sibling contexts never share the appended slot
+		st.fns = append(st.fns, old.fns...)
+	}
+	st.fns = append(st.fns, fn)
+	return context.WithValue(ctx, metaKey{}, st)
+}
+
+// Version is a middleware adding the API version to the Meta of every response
+func Version(version string) func(http.Handler) http.Handler {
+	return metaMiddleware(func(m *Meta) { m.Version = version })
+}
+
+// Deprecated is a middleware marking the responses as deprecated, notice
+// tells the clients what to use instead. The Deprecation header is set too.
+func Deprecated(notice string) func(http.Handler) http.Handler {
+	set := metaMiddleware(func(m *Meta) { m.Deprecation = notice })
+	return func(next http.Handler) http.Handler {
+		next = set(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func metaMiddleware(fn func(*Meta)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithMeta(r.Context(), fn)))
+		})
+	}
+}
+
+// meta builds the Meta of a response, the request id, latency and the
+// WithMeta fields are filled when the handler was made with For
+func (rh *ResponseHandler) meta() Meta {
+	now := time.Now()
+	m := Meta{Timestamp: now.UTC().Format(time.RFC3339)}
+	if rh.request == nil {
+		return m
+	}
+	ctx := rh.request.Context()
+	m.RequestID = goruntime.FromContext(ctx)
+	if st := metaFrom(ctx); st != nil {
+		if !st.start.IsZero() {
+			m.Latency = now.Sub(st.start).String()
+		}
+		for _, fn := range st.fns {
+			fn(&m)
+		}
+	}
+	return m
+}
@@ -0,0 +1,161 @@
+package response
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EncodeFunc writes v in the media type it is registered for
+type EncodeFunc func(w io.Writer, v any) error
+
+type encoder struct {
+	mediaType string
+	encode    EncodeFunc
+}
+
+var (
+	encodersMU sync.RWMutex
+	// in order of preference when the Accept header allows several. XML is
+	// left out, browsers list application/xml above */* and would get it
+	encoders = []encoder{
+		{"application/json", encodeJSON},
+		{"application/msgpack", encodeMsgpack},
+		{"application/vnd.msgpack", encodeMsgpack},
+		{"application/x-msgpack", encodeMsgpack},
+		{"application/cbor", encodeCBOR},
+	}
+)
+
+// RegisterEncoder adds the encoder of a media type used by content
+// negotiation, it replaces the encoder already registered for it
+//
+// Example:
+//
+//	response.RegisterEncoder("application/yaml", func(w io.Writer, v any) error {
+//		return yaml.NewEncoder(w).Encode(v)
+//	})
+//
+//	// XML is opt-in
+//	response.RegisterEncoder("application/xml", response.EncodeXML)
+func RegisterEncoder(mediaType string, fn EncodeFunc) {
+	mediaType = strings.ToLower(mediaType)
+	encodersMU.Lock()
+	defer encodersMU.Unlock()
+	for i := range encoders {
+		if encoders[i].mediaType == mediaType {
+			encoders[i].encode = fn
+			return
+		}
+	}
+	encoders = append(encoders, encoder{mediaType, fn})
+}
+
+// acceptRange is one media range of an Accept header
+type acceptRange struct {
+	typ, sub string
+	q        float64
+}
+
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		typ, sub, _ := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if typ == "" {
+			continue
+		}
+		if sub == "" {
+			sub = "*"
+		}
+		ar := acceptRange{typ: typ, sub: sub, q: 1}
+		for _, p := range params[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(k, "q") {
+				if q, err := strconv.ParseFloat(v, 64); err == nil {
+					ar.q = q
+				}
+			}
+		}
+		ranges = append(ranges, ar)
+	}
+	return ranges
+}
+
+// negotiate picks the encoder with the highest q value for the Accept header,
+// ties go to the registration order. ok is false when none is acceptable.
+func negotiate(accept string) (encoder, bool) {
+	encodersMU.RLock()
+	defer encodersMU.RUnlock()
+	if strings.TrimSpace(accept) == "" {
+		return encoders[0], true
+	}
+	ranges := parseAccept(accept)
+
+	best, bestQ := encoder{}, 0.0
+	for _, enc := range encoders {
+		typ, sub, _ := strings.Cut(enc.mediaType, "/")
+		// the most specific range matching the media type gives its q
+		q, specificity := 0.0, -1
+		for _, ar := range ranges {
+			s := -1
+			switch {
+			case ar.typ == typ && ar.sub == sub:
+				s = 2
+			case ar.typ == typ && ar.sub == "*":
+				s = 1
+			case ar.typ == "*" && ar.sub == "*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = ar.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// supportedTypes lists the registered media types, for the 406 answer
+func supportedTypes() string {
+	encodersMU.RLock()
+	defer encodersMU.RUnlock()
+	types := make([]string, len(encoders))
+	for i, enc := range encoders {
+		types[i] = enc.mediaType
+	}
+	return strings.Join(types, ", ")
+}
+
+// writeNegotiated writes v with the encoder accepted by r, JSON when r is
+// nil. When nothing is acceptable it answers 406 if strict, else JSON.
+func writeNegotiated(w http.ResponseWriter, r *http.Request, status int, v any, strict bool) error {
+	enc := encoder{"application/json", encodeJSON}
+	if r != nil {
+		w.Header().Add("Vary", "Accept")
+		e, ok := negotiate(r.Header.Get("Accept"))
+		switch {
+		case ok:
+			enc = e
+		case strict:
+			status = http.StatusNotAcceptable
+			v = Response{
+				Status:  "error",
+				Message: "Not Acceptable",
+				Error: &ErrorInfo{
+					Code:    ErrCodeNotAcceptable,
+					Details: "supported media types: " + supportedTypes(),
+				},
+				Meta: (&ResponseHandler{request: r}).meta(),
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", enc.mediaType)
+	w.WriteHeader(status)
+	return enc.encode(w, v)
+}
@@ -0,0 +1,80 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{browserAccept, "application/json"},
+		{"application/*", "application/json"},
+		{"application/cbor", "application/cbor"},
+		{"application/json;q=0.5, application/msgpack", "application/msgpack"},
+		{"application/msgpack;q=0.5, */*;q=0.9", "application/json"},
+		// the most specific range gives the q value
+		{"application/*;q=0.9, application/json;q=0.1", "application/msgpack"},
+		{"application/xml", ""},
+		{"application/json;q=0", ""},
+	}
+	for _, tt := range tests {
+		enc, ok := negotiate(tt.accept)
+		if got := enc.mediaType; !ok && tt.want != "" || ok && got != tt.want {
+			t.Errorf("negotiate(%q) = %q, %v, want %q", tt.accept, got, ok, tt.want)
+		}
+	}
+}
+
+func TestNegotiateXMLOptIn(t *testing.T) {
+	encodersMU.RLock()
+	saved := append([]encoder(nil), encoders...)
+	encodersMU.RUnlock()
+	defer func() {
+		encodersMU.Lock()
+		encoders = saved
+		encodersMU.Unlock()
+	}()
+	RegisterEncoder("application/xml", EncodeXML)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/xml")
+	rec := httptest.NewRecorder()
+	For(req).Success(rec, "OK", map[string]any{"name": "a&b"})
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/xml" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "<name>a&amp;b</name>") {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestNotAcceptable(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "text/html")
+
+	rec := httptest.NewRecorder()
+	For(req).Success(rec, "OK", nil)
+	if rec.Code != http.StatusNotAcceptable || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("success: status %d, Content-Type %q, want 406 JSON", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Header().Get("Vary"), "Accept") {
+		t.Errorf("Vary = %q", rec.Header().Get("Vary"))
+	}
+
+	// errors fall back to JSON
+	rec = httptest.NewRecorder()
+	For(req).Fail(rec, "Bad input", ErrCodeInvalidRequest, "")
+	if rec.Code != http.StatusBadRequest || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("fail: status %d, Content-Type %q, want 400 JSON", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
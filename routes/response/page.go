@@ -0,0 +1,178 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// query parameters read by ParseOffset and ParseCursor and set in the links
+const (
+	QueryLimit  = "limit"
+	QueryOffset = "offset"
+	QueryCursor = "cursor"
+)
+
+// Envelope is Response with typed data, for clients decoding the responses
+//
+// Example:
+//
+//	var env response.Envelope[[]User]
+//	err := json.NewDecoder(resp.Body).Decode(&env)
+//	// env.Data []User, env.Meta.Page.Next
+type Envelope[T any] struct {
+	Status  string     `json:"status"`
+	Message string     `json:"message"`
+	Data    T          `json:"data,omitempty"`
+	Error   *ErrorInfo `json:"error,omitempty"`
+	Meta    Meta       `json:"meta"`
+}
+
+// PageMeta is the pagination block of Meta
+type PageMeta struct {
+	// number of items of this page
+	Count int `json:"count"`
+	Limit int `json:"limit"`
+	// offset pagination only
+	Offset *int   `json:"offset,omitempty"`
+	Total  *int64 `json:"total,omitempty"`
+	// cursor pagination only
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	// links to the next and previous pages, the current request URL with
+	// the page query parameters replaced
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// Pager is data sent as a page: PageItems is the data of the response and
+// PageMeta the Meta.Page block
+type Pager interface {
+	PageItems() any
+	PageMeta(r *http.Request) *PageMeta
+}
+
+// Page is one page of items, send it with Success (or Created, Accepted)
+//
+// Example:
+//
+//	p := response.ParseOffset(r, 20, 100)
+//	users, total, err := repo.List(ctx, p.Limit, p.Offset)
+//	response.For(r).Success(w, "OK", response.OffsetPage(users, total, p))
+type Page[T any] struct {
+	Items []T
+	Limit int
+
+	// offset pagination
+	Offset int
+	// total number of items, -1 when unknown
+	Total int64
+
+	// cursor pagination, signed cursors of the next and previous pages,
+	// empty at the ends
+	NextCursor string
+	PrevCursor string
+
+	cursor bool
+}
+
+// OffsetParams are the limit and offset of the requested page
+type OffsetParams struct {
+	Limit  int
+	Offset int
+}
+
+// ParseOffset reads the limit and offset query parameters, a missing or
+// invalid limit gives defaultLimit and it is capped to maxLimit
+func ParseOffset(r *http.Request, defaultLimit, maxLimit int) OffsetParams {
+	q := r.URL.Query()
+	offset, err := strconv.Atoi(q.Get(QueryOffset))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return OffsetParams{Limit: parseLimit(q.Get(QueryLimit), defaultLimit, maxLimit), Offset: offset}
+}
+
+// ParseCursor reads the cursor and limit query parameters, see ParseOffset
+// for the limit. The cursor is returned as sent, it is not verified: decode
+// it with Cursors.Decode before use.
+func ParseCursor(r *http.Request, defaultLimit, maxLimit int) (cursor string, limit int) {
+	q := r.URL.Query()
+	return q.Get(QueryCursor), parseLimit(q.Get(QueryLimit), defaultLimit, maxLimit)
+}
+
+func parseLimit(s string, defaultLimit, maxLimit int) int {
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit <= 0 {
+		limit = defaultLimit
+	}
+	if maxLimit > 0 && limit > maxLimit {
+		limit = maxLimit
+	}
+	return limit
+}
+
+// OffsetPage returns the page of items at p, total is the number of items
+// of every page, -1 when unknown
+func OffsetPage[T any](items []T, total int64, p OffsetParams) Page[T] {
+	return Page[T]{Items: items, Limit: p.Limit, Offset: p.Offset, Total: total}
+}
+
+// CursorPage returns a page of items with the cursors of the next and
+// previous pages, empty at the ends
+func CursorPage[T any](items []T, limit int, next, prev string) Page[T] {
+	return Page[T]{Items: items, Limit: limit, Total: -1, NextCursor: next, PrevCursor: prev, cursor: true}
+}
+
+// PageItems returns the items, never nil so the data is an array
+func (p Page[T]) PageItems() any {
+	if p.Items == nil {
+		return []T{}
+	}
+	return p.Items
+}
+
+// PageMeta returns the pagination block, the links are built when r is set
+func (p Page[T]) PageMeta(r *http.Request) *PageMeta {
+	meta := &PageMeta{Count: len(p.Items), Limit: p.Limit}
+	if p.Total >= 0 {
+		total := p.Total
+		meta.Total = &total
+	}
+
+	if p.cursor {
+		meta.NextCursor, meta.PrevCursor = p.NextCursor, p.PrevCursor
+		if p.NextCursor != "" {
+			meta.Next = pageLink(r, QueryCursor, p.NextCursor, p.Limit)
+		}
+		if p.PrevCursor != "" {
+			meta.Prev = pageLink(r, QueryCursor, p.PrevCursor, p.Limit)
+		}
+		return meta
+	}
+
+	offset := p.Offset
+	meta.Offset = &offset
+	hasNext := len(p.Items) >= p.Limit && p.Limit > 0
+	if p.Total >= 0 {
+		hasNext = int64(p.Offset+p.Limit) < p.Total
+	}
+	if hasNext {
+		meta.Next = pageLink(r, QueryOffset, strconv.Itoa(p.Offset+p.Limit), p.Limit)
+	}
+	if p.Offset > 0 {
+		meta.Prev = pageLink(r, QueryOffset, strconv.Itoa(max(p.Offset-p.Limit, 0)), p.Limit)
+	}
+	return meta
+}
+
+// pageLink returns the path and query of r with key and the limit set,
+// empty without request
+func pageLink(r *http.Request, key, value string, limit int) string {
+	if r == nil {
+		return ""
+	}
+	q := r.URL.Query()
+	q.Set(key, value)
+	q.Set(QueryLimit, strconv.Itoa(limit))
+	return r.URL.Path + "?" + q.Encode()
+}
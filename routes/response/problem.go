@@ -0,0 +1,89 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ContentTypeProblem is the media type of RFC 9457 problem details
+const ContentTypeProblem = "application/problem+json"
+
+// ProblemRenderer writes errors as RFC 9457 problem details:
+//
+//	{
+//	  "type": "https://errors.example.com/validation-error",
+//	  "title": "Validation failed",
+//	  "status": 400,
+//	  "detail": "name must be at least 3 characters",
+//	  "instance": "urn:request:4f1c...",
+//	  "code": "VALIDATION_ERROR",
+//	  "errors": [{"field": "name", "rule": "min", ...}]
+//	}
+//
+// ErrorInfo.Extensions are added as extension members, an extension named
+// like a standard member is dropped.
+type ProblemRenderer struct {
+	// base URI of the type member, the code in lower case with "-" is
+	// appended (VALIDATION_ERROR -> validation-error). Empty gives
+	// "about:blank" for codes missing from Types
+	TypeBase string
+	// type URI per ErrorInfo.Code, checked before TypeBase
+	Types map[string]string
+	// builds the instance member from the request id, nil gives
+	// "urn:request:<id>". instance is left out without request id
+	Instance func(requestID string) string
+}
+
+func (p ProblemRenderer) RenderError(w http.ResponseWriter, r *http.Request, status int, resp Response) error {
+	info := resp.Error
+	if info == nil {
+		info = &ErrorInfo{}
+	}
+	problem := map[string]any{
+		"status": status,
+		"title":  resp.Message,
+	}
+	for k, v := range info.Extensions {
+		if !problemMembers[k] {
+			problem[k] = v
+		}
+	}
+	problem["type"] = p.typeURI(info.Code)
+	if info.Code != "" {
+		problem["code"] = info.Code
+	}
+	if info.Details != "" {
+		problem["detail"] = info.Details
+	}
+	if len(info.Fields) > 0 {
+		problem["errors"] = info.Fields
+	}
+	if id := resp.Meta.RequestID; id != "" {
+		if p.Instance != nil {
+			problem["instance"] = p.Instance(id)
+		} else {
+			problem["instance"] = "urn:request:" + id
+		}
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(problem)
+}
+
+// problemMembers are the members an extension can not replace
+var problemMembers = map[string]bool{
+	"type": true, "title": true, "status": true, "detail": true,
+	"instance": true, "code": true, "errors": true,
+}
+
+func (p ProblemRenderer) typeURI(code string) string {
+	if uri, ok := p.Types[code]; ok {
+		return uri
+	}
+	if p.TypeBase == "" || code == "" {
+		return "about:blank"
+	}
+	return p.TypeBase + strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+}
@@ -0,0 +1,125 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/he-end/simproute/goruntime"
+)
+
+func renderProblem(t *testing.T, p ProblemRenderer, status int, resp Response) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	if err := p.RenderError(rec, nil, status, resp); err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %s: %v", rec.Body, err)
+	}
+	return rec, body
+}
+
+func TestProblemFields(t *testing.T) {
+	resp := Response{
+		Status:  "fail",
+		Message: "Validation failed",
+		Error: &ErrorInfo{
+			Code:    ErrCodeValidationError,
+			Details: "name must be at least 3 characters",
+			Fields:  []FieldError{{Field: "name", Rule: "min", Param: "3", Message: "name must be at least 3 characters"}},
+			// extensions never replace the standard members
+			Extensions: map[string]any{"retry_after": 30.0, "status": 999.0, "title": "x"},
+		},
+		Meta: Meta{RequestID: "abc-123"},
+	}
+	rec, body := renderProblem(t, ProblemRenderer{TypeBase: "https://errors.example.com/"}, http.StatusBadRequest, resp)
+
+	if ct := rec.Header().Get("Content-Type"); ct != ContentTypeProblem {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d", rec.Code)
+	}
+	want := map[string]any{
+		"type":        "https://errors.example.com/validation-error",
+		"title":       "Validation failed",
+		"status":      400.0,
+		"detail":      "name must be at least 3 characters",
+		"instance":    "urn:request:abc-123",
+		"code":        ErrCodeValidationError,
+		"retry_after": 30.0,
+		"errors": []any{map[string]any{
+			"field": "name", "rule": "min", "param": "3", "message": "name must be at least 3 characters",
+		}},
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("problem =\n%v\nwant\n%v", body, want)
+	}
+}
+
+func TestProblemType(t *testing.T) {
+	tests := []struct {
+		name string
+		p    ProblemRenderer
+		code string
+		want string
+	}{
+		{"no base", ProblemRenderer{}, ErrCodeNoDataFound, "about:blank"},
+		{"base", ProblemRenderer{TypeBase: "https://e.com/"}, ErrCodeNoDataFound, "https://e.com/no-data"},
+		{"types first", ProblemRenderer{TypeBase: "https://e.com/", Types: map[string]string{"NO_DATA": "https://e.com/missing"}}, ErrCodeNoDataFound, "https://e.com/missing"},
+		{"no code", ProblemRenderer{TypeBase: "https://e.com/"}, "", "about:blank"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body := renderProblem(t, tt.p, http.StatusNotFound, Response{Message: "x", Error: &ErrorInfo{Code: tt.code}})
+			if body["type"] != tt.want {
+				t.Errorf("type = %v, want %s", body["type"], tt.want)
+			}
+			if _, ok := body["code"]; ok != (tt.code != "") {
+				t.Errorf("code member present = %v", ok)
+			}
+		})
+	}
+}
+
+func TestProblemInstance(t *testing.T) {
+	// no request id, no instance; optional members are left out
+	_, body := renderProblem(t, ProblemRenderer{}, http.StatusInternalServerError, Response{Message: "boom"})
+	for _, member := range []string{"instance", "detail", "errors"} {
+		if _, ok := body[member]; ok {
+			t.Errorf("%s present without value: %v", member, body)
+		}
+	}
+
+	p := ProblemRenderer{Instance: func(id string) string { return "/requests/" + id }}
+	_, body = renderProblem(t, p, http.StatusNotFound, Response{Message: "x", Meta: Meta{RequestID: "r1"}})
+	if body["instance"] != "/requests/r1" {
+		t.Errorf("instance = %v", body["instance"])
+	}
+}
+
+// the request id of For(r) ends in instance
+func TestProblemFromResponseHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(goruntime.NewContext(req.Context(), "req-42"))
+	rec := httptest.NewRecorder()
+
+	rh := For(req)
+	rh.Renderer = ProblemRenderer{}
+	rh.Fail(rec, "Bad input", ErrCodeInvalidRequest, "id must be a number")
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Header().Get("Content-Type") != ContentTypeProblem || rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if body["instance"] != "urn:request:req-42" || body["detail"] != "id must be a number" || body["title"] != "Bad input" {
+		t.Errorf("problem = %v", body)
+	}
+}
@@ -0,0 +1,47 @@
+package response
+
+import (
+	"net/http"
+	"sync"
+)
+
+// ErrorRenderer writes the responses of Fail and Error
+type ErrorRenderer interface {
+	// RenderError writes resp with status, r is nil when the
+	// ResponseHandler is not bound to a request
+	RenderError(w http.ResponseWriter, r *http.Request, status int, resp Response) error
+}
+
+// EnvelopeRenderer writes the Response envelope (default) in the format
+// accepted by the request, JSON when none is
+type EnvelopeRenderer struct{}
+
+func (EnvelopeRenderer) RenderError(w http.ResponseWriter, r *http.Request, status int, resp Response) error {
+	return writeNegotiated(w, r, status, resp, false)
+}
+
+var (
+	rendererMU sync.RWMutex
+	renderer   ErrorRenderer = EnvelopeRenderer{}
+)
+
+// SetErrorRenderer sets the renderer of every ResponseHandler without its
+// own, the router answers 404, 405 and recovered panics with it too
+//
+// Example:
+//
+//	response.SetErrorRenderer(response.ProblemRenderer{TypeBase: "https://errors.example.com/"})
+func SetErrorRenderer(r ErrorRenderer) {
+	if r == nil {
+		r = EnvelopeRenderer{}
+	}
+	rendererMU.Lock()
+	renderer = r
+	rendererMU.Unlock()
+}
+
+func errorRenderer() ErrorRenderer {
+	rendererMU.RLock()
+	defer rendererMU.RUnlock()
+	return renderer
+}
@@ -1,11 +1,11 @@
 package response
 
 import (
-	"encoding/json"
 	"net/http"
-	"time"
 
+	"github.com/he-end/simproute/goruntime"
 	logger "github.com/he-end/simproute/route_logger"
+	"github.com/he-end/simproute/routes/i18n"
 	"go.uber.org/zap"
 )
 
@@ -22,32 +22,81 @@ type ResponseNoContent string
 
 // ErrorInfo contains error details
 type ErrorInfo struct {
-	Code    string `json:"code"`
-	Details string `json:"details"`
+	Code    string       `json:"code"`
+	Details string       `json:"details"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	// extra members, e.g. a retry delay or a documentation link
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// FieldError tells which field of the request failed which rule
+type FieldError struct {
+	// path of the field, e.g. "email" or "items[0].name"
+	Field string `json:"field"`
+	// failed rule, e.g. "required" or "min"
+	Rule string `json:"rule"`
+	// rule parameter, e.g. "3" for min=3
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+	// catalog key of Message, {field} and {param} are its params. Set by
+	// validate for the built-in rules, localized responses use its text
+	MessageKey string `json:"-"`
 }
 
 // Meta contains metadata about the response
 type Meta struct {
+	// correlation id of the request, set when the handler was made with For
 	RequestID string `json:"request_id,omitempty"`
 	Timestamp string `json:"timestamp"`
+	// API version serving the request
+	Version string `json:"version,omitempty"`
+	// time spent since the router received the request, e.g. "1.52ms"
+	Latency string `json:"latency,omitempty"`
+	// set when the endpoint is deprecated, tells what to use instead
+	Deprecation string `json:"deprecation,omitempty"`
+	// custom members, see WithMeta
+	Extra map[string]any `json:"extra,omitempty"`
+	// set when the data is a Page
+	Page *PageMeta `json:"page,omitempty"`
 }
 
 // ResponseHandler handles API responses
 type ResponseHandler struct {
 	logger *zap.Logger
-	Dev    bool
+	// shows the details of 5xx responses, follows the runtime mode of
+	// goruntime (the router Mode with For) unless changed
+	Dev bool
+	// writes Fail and Error responses, nil uses the renderer set with
+	// SetErrorRenderer
+	Renderer ErrorRenderer
+	// localizes the Fail and Error messages, nil uses the catalog set with
+	// SetCatalog
+	Catalog *i18n.Catalog
+	// set by For, the response format is negotiated from its Accept header
+	request *http.Request
 }
 
 // NewWithGlobalLogger creates a new ResponseHandler using the global logger
 func NewWithGlobalLogger() *ResponseHandler {
-	// try to get environment
-	onDev := false
 	return &ResponseHandler{
 		logger: logger.GetLogger(),
-		Dev:    onDev,
+		Dev:    goruntime.IsDev(),
 	}
 }
 
+// For creates a ResponseHandler using the global logger for the request r,
+// the format of the responses follows its Accept header
+//
+// Example:
+//
+//	response.For(r).Success(w, "OK", user)
+func For(r *http.Request) *ResponseHandler {
+	rh := NewWithGlobalLogger()
+	rh.Dev = goruntime.ModeFromContext(r.Context()) == goruntime.ModeDev
+	rh.request = r
+	return rh
+}
+
 // Success sends a successful response
 func (rh *ResponseHandler) Success(w http.ResponseWriter, message string, data interface{}) {
 	// requestID := uuid.New().String()
@@ -56,12 +105,11 @@ func (rh *ResponseHandler) Success(w http.ResponseWriter, message string, data i
 		Status:  "success",
 		Message: message,
 		Data:    data,
-		Meta: Meta{
-			// RequestID: requestID,
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
-		},
+		Meta:    rh.meta(),
 	}
 
+	rh.withPage(&response)
+
 	// rh.logger.Info("API Success Response",
 	// zap.String("request_id", requestID),
 	// zap.String("message", message),
@@ -78,12 +126,11 @@ func (rh *ResponseHandler) Accepted(w http.ResponseWriter, message string, data
 		Status:  "accepted",
 		Message: message,
 		Data:    data,
-		Meta: Meta{
-			// RequestID: requestID,
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
-		},
+		Meta:    rh.meta(),
 	}
 
+	rh.withPage(&response)
+
 	// rh.logger.Info("API Success Response",
 	// zap.String("request_id", requestID),
 	// zap.String("message", message),
@@ -100,19 +147,20 @@ func (rh *ResponseHandler) Created(w http.ResponseWriter, resoureceLocation stri
 		Status:  "created",
 		Message: message,
 		Data:    data,
-		Meta: Meta{
-			// RequestID: requestID,
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
-		},
+		Meta:    rh.meta(),
 	}
 
+	rh.withPage(&response)
+
 	// rh.logger.Info("API Success Response",
 	// zap.String("request_id", requestID),
 	// zap.String("message", message),
 	// zap.String("status", "created"),
 	// )
 	//
-	w.Header().Add("Location", resoureceLocation)
+	if resoureceLocation != "" {
+		w.Header().Add("Location", resoureceLocation)
+	}
 	rh.writeJSON(w, http.StatusCreated, response)
 }
 
@@ -131,22 +179,21 @@ func (rh *ResponseHandler) SuccessNoContent(w http.ResponseWriter, message strin
 }
 
 // Fail sends a failure response (business logic failure)
-func (rh *ResponseHandler) Fail(w http.ResponseWriter, message string, errCode string, details string) {
+// fields lists the request fields that failed, e.g. from validate.Struct
+func (rh *ResponseHandler) Fail(w http.ResponseWriter, message string, errCode string, details string, fields ...FieldError) {
 	// requestID := uuid.New().String()
 
 	errorInfo := &ErrorInfo{
 		Code:    errCode,
 		Details: details,
+		Fields:  fields,
 	}
 
 	response := Response{
 		Status:  "fail",
 		Message: message,
 		Error:   errorInfo,
-		Meta: Meta{
-			// RequestID: requestID,
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
-		},
+		Meta:    rh.meta(),
 	}
 
 	// rh.logger.Warn("API Fail Response",
@@ -155,32 +202,32 @@ func (rh *ResponseHandler) Fail(w http.ResponseWriter, message string, errCode s
 	// zap.String("error_code", errCode),
 	// zap.String("status", "fail"),
 	// )
-	rh.writeJSON(w, http.StatusBadRequest, response)
+	rh.renderError(w, http.StatusBadRequest, response)
 }
 
 // Error sends an error response (system/server error)
 func (rh *ResponseHandler) Error(w http.ResponseWriter, message string, errCode string, details string, httpStatus int) {
-	// requestID := uuid.New().String()
+	rh.ErrorDetail(w, httpStatus, message, ErrorInfo{Code: errCode, Details: details})
+}
 
-	errorInfo := &ErrorInfo{
-		Code:    errCode,
-		Details: details,
-	}
+// ErrorDetail sends an error response with the full ErrorInfo, e.g. with
+// Fields or Extensions
+func (rh *ResponseHandler) ErrorDetail(w http.ResponseWriter, httpStatus int, message string, info ErrorInfo) {
+	// requestID := uuid.New().String()
 
 	response := Response{
 		Status:  "error",
 		Message: message,
-		Error:   errorInfo,
-		Meta: Meta{
-			// RequestID: requestID,
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
-		},
+		Error:   &info,
+		Meta:    rh.meta(),
 	}
 
 	// In production, don't expose sensitive error details
 	if !rh.Dev && httpStatus >= 500 {
 		response.Message = "Internal server error"
 		response.Error.Details = "An unexpected error occurred"
+		response.Error.Fields = nil
+		response.Error.Extensions = nil
 	}
 
 	// rh.logger.Error("API Error Response",
@@ -191,15 +238,33 @@ func (rh *ResponseHandler) Error(w http.ResponseWriter, message string, errCode
 	// 	zap.String("status", "error"),
 	// )
 
-	rh.writeJSON(w, httpStatus, response)
+	rh.renderError(w, httpStatus, response)
 }
 
-// writeJSON writes JSON response to the response writer
-func (rh *ResponseHandler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+// withPage moves a Pager data into the data and Meta.Page of response
+func (rh *ResponseHandler) withPage(response *Response) {
+	if p, ok := response.Data.(Pager); ok {
+		response.Data = p.PageItems()
+		response.Meta.Page = p.PageMeta(rh.request)
+	}
+}
+
+// renderError writes an error response, localized, with the configured renderer
+func (rh *ResponseHandler) renderError(w http.ResponseWriter, status int, response Response) {
+	rh.localize(w, &response)
+	renderer := rh.Renderer
+	if renderer == nil {
+		renderer = errorRenderer()
+	}
+	if err := renderer.RenderError(w, rh.request, status, response); err != nil {
+		rh.logger.Error("Failed to render error response", zap.Error(err))
+	}
+}
 
-	if err := json.NewEncoder(w).Encode(v); err != nil {
+// writeJSON writes the response in the format accepted by the request
+// (JSON without request), 406 when no format is acceptable
+func (rh *ResponseHandler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	if err := writeNegotiated(w, rh.request, status, v, true); err != nil {
 		rh.logger.Error("Failed to encode JSON response",
 			zap.Error(err),
 		)
@@ -262,6 +327,7 @@ const (
 	ErrCodeMissingFieldJSON           = "MISSING_FIELDS_JSON"
 	ErrCodeNoDataFound                = "NO_DATA"
 	ErrCodeUnset                      = "UNSET"
+	ErrCodeNotAcceptable              = "NOT_ACCEPTABLE"
 )
 
 // Common messages
@@ -2,6 +2,11 @@ package routeutil
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 // Context key for route parameters
@@ -29,3 +34,97 @@ func (rp RouteParams) Get(key string) string {
 func SetRouteParams(ctx context.Context, params RouteParams) context.Context {
 	return context.WithValue(ctx, routeParamsKey{}, params)
 }
+
+// ErrParamNotFound is returned by the typed getters when the parameter is not in the route
+var ErrParamNotFound = errors.New("route param not found")
+
+// ParamError describes a parameter that is missing or cannot be converted
+type ParamError struct {
+	Name  string
+	Value string
+	Err   error
+}
+
+func (e *ParamError) Error() string {
+	if errors.Is(e.Err, ErrParamNotFound) {
+		return "route param " + strconv.Quote(e.Name) + ": not found"
+	}
+	return "route param " + strconv.Quote(e.Name) + ": invalid value " + strconv.Quote(e.Value) + ": " + e.Err.Error()
+}
+
+func (e *ParamError) Unwrap() error { return e.Err }
+
+func (rp RouteParams) lookup(key string) (string, error) {
+	v, ok := rp[key]
+	if !ok {
+		return "", &ParamError{Name: key, Err: ErrParamNotFound}
+	}
+	return v, nil
+}
+
+// Int parses a parameter as int
+// Usage: id, err := routeutil.GetRouteParams(r.Context()).Int("id")
+func (rp RouteParams) Int(key string) (int, error) {
+	v, err := rp.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, &ParamError{Name: key, Value: v, Err: err}
+	}
+	return n, nil
+}
+
+// Int64 parses a parameter as int64
+func (rp RouteParams) Int64(key string) (int64, error) {
+	v, err := rp.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, &ParamError{Name: key, Value: v, Err: err}
+	}
+	return n, nil
+}
+
+// Uint parses a parameter as uint64
+func (rp RouteParams) Uint(key string) (uint64, error) {
+	v, err := rp.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, &ParamError{Name: key, Value: v, Err: err}
+	}
+	return n, nil
+}
+
+// UUID parses a parameter as uuid
+// Usage: id, err := routeutil.GetRouteParams(r.Context()).UUID("id")
+func (rp RouteParams) UUID(key string) (uuid.UUID, error) {
+	v, err := rp.lookup(key)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return uuid.Nil, &ParamError{Name: key, Value: v, Err: err}
+	}
+	return id, nil
+}
+
+// Date parses a parameter formatted as YYYY-MM-DD
+func (rp RouteParams) Date(key string) (time.Time, error) {
+	v, err := rp.lookup(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, &ParamError{Name: key, Value: v, Err: err}
+	}
+	return t, nil
+}
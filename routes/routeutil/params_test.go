@@ -0,0 +1,95 @@
+package routeutil
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var params = RouteParams{
+	"id":    "42",
+	"neg":   "-7",
+	"big":   "18446744073709551615",
+	"word":  "abc",
+	"uuid":  "0b9f1c5e-3b5a-4c1e-9d2a-2f3e4d5c6b7a",
+	"day":   "2026-10-17",
+	"empty": "",
+}
+
+func TestRouteParamsAccessors(t *testing.T) {
+	tests := []struct {
+		name    string
+		get     func() (any, error)
+		want    any
+		invalid bool // ParamError with the value
+		missing bool // ErrParamNotFound
+	}{
+		{"int", func() (any, error) { return params.Int("id") }, 42, false, false},
+		{"int negative", func() (any, error) { return params.Int("neg") }, -7, false, false},
+		{"int word", func() (any, error) { return params.Int("word") }, 0, true, false},
+		{"int empty", func() (any, error) { return params.Int("empty") }, 0, true, false},
+		{"int missing", func() (any, error) { return params.Int("nope") }, 0, false, true},
+		{"int64 big", func() (any, error) { return params.Int64("big") }, int64(0), true, false},
+		{"int64", func() (any, error) { return params.Int64("neg") }, int64(-7), false, false},
+		{"uint big", func() (any, error) { return params.Uint("big") }, uint64(18446744073709551615), false, false},
+		{"uint negative", func() (any, error) { return params.Uint("neg") }, uint64(0), true, false},
+		{"uint missing", func() (any, error) { return params.Uint("nope") }, uint64(0), false, true},
+		{"uuid", func() (any, error) { return params.UUID("uuid") }, uuid.MustParse("0b9f1c5e-3b5a-4c1e-9d2a-2f3e4d5c6b7a"), false, false},
+		{"uuid word", func() (any, error) { return params.UUID("word") }, uuid.Nil, true, false},
+		{"uuid missing", func() (any, error) { return params.UUID("nope") }, uuid.Nil, false, true},
+		{"date", func() (any, error) { return params.Date("day") }, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), false, false},
+		{"date number", func() (any, error) { return params.Date("id") }, time.Time{}, true, false},
+		{"date missing", func() (any, error) { return params.Date("nope") }, time.Time{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.get()
+			if got != tt.want {
+				t.Errorf("value = %v, want %v", got, tt.want)
+			}
+			var pe *ParamError
+			switch {
+			case tt.missing:
+				if !errors.Is(err, ErrParamNotFound) || !errors.As(err, &pe) || pe.Name != "nope" {
+					t.Errorf("err = %v, want ErrParamNotFound for nope", err)
+				}
+			case tt.invalid:
+				if !errors.As(err, &pe) || errors.Is(err, ErrParamNotFound) || pe.Value != params[pe.Name] {
+					t.Errorf("err = %v, want a ParamError with the value", err)
+				}
+			default:
+				if err != nil {
+					t.Errorf("err = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestParamErrorMessage(t *testing.T) {
+	_, err := params.Int("word")
+	if want := `route param "word": invalid value "abc": strconv.Atoi: parsing "abc": invalid syntax`; err.Error() != want {
+		t.Errorf("Error = %q, want %q", err, want)
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Errorf("%v does not wrap the parse error", err)
+	}
+	if _, err := params.Int("nope"); err.Error() != `route param "nope": not found` {
+		t.Errorf("Error = %q", err)
+	}
+}
+
+func TestRouteParamsContext(t *testing.T) {
+	if got := GetRouteParams(context.Background()); got == nil || len(got) != 0 {
+		t.Errorf("GetRouteParams without params = %v, want empty", got)
+	}
+	ctx := SetRouteParams(context.Background(), RouteParams{"id": "1"})
+	if got := GetRouteParams(ctx).Get("id"); got != "1" {
+		t.Errorf("Get = %q", got)
+	}
+}
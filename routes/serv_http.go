@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"fmt"
 	"net/http"
+	runtimedebug "runtime/debug"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/he-end/simproute/goruntime"
@@ -27,136 +31,133 @@ func (rr *responseRecorer) Write(b []byte) (int, error) {
 		rr.status = http.StatusOK
 	}
 	n, err := rr.ResponseWriter.Write(b)
-	rr.size = n
+	rr.size += n
 	return n, err
 }
 
+// Unwrap lets http.ResponseController reach the original writer (Flush, Hijack, ...)
+func (rr *responseRecorer) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	start := time.Now()
+	r.MU.RLock()
+	handler := r.handler
+	r.MU.RUnlock()
+
+	if handler == nil {
+		handler = r.buildHandler()
+	}
+	handler.ServeHTTP(w, req)
+}
 
-	rec := &responseRecorer{ResponseWriter: w, status: http.StatusOK}
+// Freeze builds the middleware chain now instead of on the first request,
+// Use panics after the router is frozen
+func (r *Router) Freeze() {
+	r.buildHandler()
+	r.MU.Lock()
+	r.frozen = true
+	r.MU.Unlock()
+}
 
-	if r.AutoCorelation {
-		r.Use(mwAutoCorelation())
-		defer goruntime.ClearCorelationID()
-		defer logger.DeferDeleteRuntimeValue()
+// mwStart records when the request was received, the response Meta reports
+// the latency from it, and the mode of the router for its responses
+func mwStart(mode goruntime.Mode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := response.WithStart(req.Context(), time.Now())
+			next.ServeHTTP(w, req.WithContext(goruntime.NewModeContext(ctx, mode)))
+		})
 	}
-	defer func() {
-		// panic recovered
-		if r.RecoverOnPanic {
-			defer func() {
-				if recvr := recover(); recvr != nil {
-					fields := []zap.Field{zap.Any("error", recvr), zap.String("method", req.Method), zap.String("path", req.URL.Path)}
-					if r.AutoCorelation {
-						rID := goruntime.GetCorelationID()
-						fields = append(fields, zap.String("request_id", rID.String()))
-					}
-					logger.Error("panic recovered",
-						fields...,
-					)
-					// Use response handler to send a safe error response
-					response.NewWithGlobalLogger().Error(rec, "Internal server error", response.ErrCodeInternalError, "An unexpected error occurred", http.StatusInternalServerError)
-				}
-			}()
-			if rec.status == 0 {
-				rec.status = http.StatusOK
-			}
-			dur := time.Since(start)
-			fields := []zap.Field{
-				zap.String("method", req.Method),
-				zap.String("path", req.URL.Path),
-				zap.Int("status", rec.status),
-				zap.String("ip", req.RemoteAddr),
-				zap.Duration("duration", dur),
-			}
-			if r.AutoCorelation {
-				rID := goruntime.GetCorelationID()
-				fields = append(fields, zap.String("request_id", rID.String()))
-			}
+}
 
-			// its call external package 'logger' for create auto log
-			logger.GetLogger().Info("http_request", fields...)
+// buildHandler composes the request pipeline once:
+//
+//	start time and mode -> correlation -> tracing -> access log -> panic recovery -> Mws -> route lookup
+func (r *Router) buildHandler() http.Handler {
+	r.MU.Lock()
+	defer r.MU.Unlock()
+	if r.handler != nil {
+		return r.handler
+	}
 
-		}
-	}()
+	var handler http.Handler = http.HandlerFunc(r.dispatch)
+	// Wrap handler with middleware chain (outer-most first registered)
+	for i := len(r.Mws) - 1; i >= 0; i-- {
+		handler = r.Mws[i](handler)
+	}
+	if r.RecoverOnPanic {
+		handler = mwRecover(r.PanicHandler, r.DebugErrors && r.Mode == goruntime.ModeDev)(handler)
+	}
+	if r.AccessLog {
+		handler = mwAccessLog()(handler)
+	}
+	if r.Tracer != nil {
+		handler = mwTrace(r.Tracer)(handler)
+	}
+	if r.AutoCorelation {
+		handler = mwAutoCorelation(r.Corelation)(handler)
+	}
+	handler = mwStart(r.Mode)(handler)
+
+	r.handler = handler
+	return handler
+}
 
+// dispatch finds the route for the request and calls its handler
+func (r *Router) dispatch(w http.ResponseWriter, req *http.Request) {
 	// Lookup
 	method := req.Method
 	path := req.URL.Path
 
-	// Handle preflight OPTIONS
-	if method == http.MethodOptions {
-		if r.handleOptions(w, req) {
-			return
+	r.MU.RLock()
+	handler, pattern, routeParams := r.match(path, method)
+	// HEAD is served by the GET handler when not registered
+	if handler == nil && method == http.MethodHead {
+		if handler, pattern, routeParams = r.match(path, http.MethodGet); handler != nil {
+			handler = headHandler(handler)
 		}
 	}
-
-	// r.MU.RLock()
-	// // snapshot middleware slice for this request
-	// mws := append([]func(http.Handler) http.Handler(nil), r.Mws...)
-	// // snapshot routes for this request
-	// routes := make(map[string]map[string]http.Handler)
-	// for p, methods := range r.Routes {
-	// 	routes[p] = methods
-	// }
-	// // snapshot dynamic routes for this request
-	// dynamicRoutes := make([]struct {
-	// 	pattern routePattern
-	// 	method  map[string]http.Handler
-	// }, len(r.DynamicRoutes))
-
-	// copy(dynamicRoutes, r.DynamicRoutes)
-	r.MU.RLock()
-
-	var handler http.Handler
-	var routeParams routeutil.RouteParams
-
-	// First, try exact match (static routes)
-	if methodForPath, exist := r.Routes[req.URL.Path]; exist {
-		handler = methodForPath[req.Method]
+	var allow []string
+	var options http.Handler
+	if handler == nil {
+		var resource string
+		allow, resource = r.allowedMethods(path)
+		options = r.options[resource]
 	}
-	// else {
-	// 	// 405 Method Not Allowed
-	// 	response.NewWithGlobalLogger().Fail(rec, "Method Not Allowed", "METHOD_NOT_ALLOWED", "The method is not allowed for the requested URL")
-	// 	return
-	// }
+	r.MU.RUnlock()
 
 	if handler == nil {
-		// Try dynamic route matching
-		found := false
-		for _, dr := range r.DynamicRoutes {
-			if matches := dr.pattern.regex.FindStringSubmatch(path); matches != nil {
-				// Extract parameters
-				if methodHandler, exists := dr.method[method]; exists {
-					routeParams = make(routeutil.RouteParams)
-					for i, paramName := range dr.pattern.paramNames {
-						if i+1 < len(matches) {
-							routeParams[paramName] = matches[i+1]
-						}
-					}
-
-					// Check if method is allowed for this pattern
-					handler = methodHandler
-					found = true
-					break
-				} else {
-					r.MU.RUnlock()
-					// Pattern matches but method not allowed - 405
-					response.NewWithGlobalLogger().Fail(rec, "Method Not Allowed", "METHOD_NOT_ALLOWED", "The method is not allowed for the requested URL")
-					return
-				}
+		if len(allow) == 0 {
+			// 404 Not Found
+			if r.NotFound != nil {
+				r.NotFound.ServeHTTP(w, req)
+				return
+			}
+			response.For(req).Error(w, "Not Found", "NOT_FOUND", "The requested resource was not found", http.StatusNotFound)
+			return
+		}
+		// Handle preflight OPTIONS, through the middleware of the route group
+		if method == http.MethodOptions {
+			if options == nil {
+				options = http.HandlerFunc(r.handleOptions)
 			}
+			options.ServeHTTP(w, req)
+			return
 		}
-
-		if !found {
-			// 404 Not Found
-			r.MU.RUnlock()
-			response.NewWithGlobalLogger().Error(rec, "Not Found", "NOT_FOUND", "The requested resource was not found", http.StatusNotFound)
+		// Path matches but method not allowed - 405
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		if r.MethodNotAllowed != nil {
+			r.MethodNotAllowed.ServeHTTP(w, req)
 			return
 		}
+		response.For(req).Error(w, "Method Not Allowed", "METHOD_NOT_ALLOWED", "The method is not allowed for the requested URL", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if st := routeStateFrom(req.Context()); st != nil {
+		st.pattern = pattern
 	}
-	currentMws := r.Mws
-	r.MU.RUnlock()
 
 	// Inject route parameters into request context
 	if routeParams != nil {
@@ -164,10 +165,177 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		req = req.WithContext(ctx)
 	}
 
-	// Wrap handler with middleware chain (outer-most last registered)
-	for i := len(currentMws) - 1; i >= 0; i-- {
-		handler = currentMws[i](handler)
+	handler.ServeHTTP(w, req)
+}
+
+// match returns the handler, pattern and params for path and method,
+// static routes first, r.MU must be held
+func (r *Router) match(path, method string) (http.Handler, string, routeutil.RouteParams) {
+	// First, try exact match (static routes)
+	if methodForPath, exist := r.Routes[path]; exist {
+		if handler := methodForPath[method]; handler != nil {
+			return handler, path, nil
+		}
 	}
 
-	handler.ServeHTTP(rec, req)
+	// Try dynamic route matching
+	if r.tree != nil {
+		if lf, params, _ := r.tree.lookup(path, method); lf != nil {
+			return lf.methods[method], lf.pattern.pattern, params
+		}
+	}
+	return nil, "", nil
+}
+
+// allowedMethods lists the methods registered for path, sorted, with HEAD
+// when GET is registered and OPTIONS, nil when no route matches the path.
+// resource is the static path or the first dynamic pattern matching path.
+// r.MU must be held
+func (r *Router) allowedMethods(path string) (allow []string, resource string) {
+	seen := make(map[string]struct{})
+	if methods, ok := r.Routes[path]; ok {
+		resource = path
+		for method := range methods {
+			seen[method] = struct{}{}
+		}
+	}
+	if r.tree != nil {
+		_, _, leaves := r.tree.lookup(path, "")
+		for _, lf := range leaves {
+			if resource == "" {
+				resource = lf.pattern.pattern
+			}
+			for method := range lf.methods {
+				seen[method] = struct{}{}
+			}
+		}
+	}
+	if len(seen) == 0 {
+		return nil, ""
+	}
+	if _, ok := seen[http.MethodGet]; ok {
+		seen[http.MethodHead] = struct{}{}
+	}
+	seen[http.MethodOptions] = struct{}{}
+
+	methods := make([]string, 0, len(seen))
+	for method := range seen {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods, resource
+}
+
+// headHandler runs a GET handler for a HEAD request without sending the body
+func headHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(&headResponseWriter{ResponseWriter: w}, req)
+	})
+}
+
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+// Write drops the body, the length is still reported as written
+func (hw *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (hw *headResponseWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
+
+// mwAccessLog writes one "http_request" log line per request
+func mwAccessLog() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			rec := recorderOf(w)
+			defer func() {
+				fields := []zap.Field{
+					zap.String("method", req.Method),
+					zap.String("path", req.URL.Path),
+					zap.Int("status", rec.status),
+					zap.String("ip", req.RemoteAddr),
+					zap.Duration("duration", time.Since(start)),
+				}
+				// request_id comes with the request logger
+				logger.Ctx(req.Context()).Info("http_request", fields...)
+			}()
+			next.ServeHTTP(rec, req)
+		})
+	}
+}
+
+// mwRecover turns a panic into a 500 response, written by onPanic when set.
+// With debug the response shows the panic, its error chain and stack
+func mwRecover(onPanic func(w http.ResponseWriter, r *http.Request, recovered any, stack []byte), debug bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			defer func() {
+				recvr := recover()
+				if recvr == nil {
+					return
+				}
+				if recvr == http.ErrAbortHandler {
+					panic(recvr)
+				}
+				stack := runtimedebug.Stack()
+				logger.Ctx(req.Context()).Error("panic recovered",
+					zap.Any("error", recvr),
+					zap.String("method", req.Method),
+					zap.String("path", req.URL.Path),
+				)
+				if onPanic != nil {
+					onPanic(w, req, recvr, stack)
+					return
+				}
+				if debug {
+					response.For(req).ErrorDetail(w, http.StatusInternalServerError, "Internal server error", panicInfo(recvr, stack))
+					return
+				}
+				// Use response handler to send a safe error response
+				response.For(req).Error(w, "Internal server error", response.ErrCodeInternalError, "An unexpected error occurred", http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// panicInfo describes a recovered panic for the dev 500 response
+func panicInfo(recovered any, stack []byte) response.ErrorInfo {
+	ext := map[string]any{
+		"stack": strings.Split(strings.TrimSpace(string(stack)), "\n"),
+	}
+	if err, ok := recovered.(error); ok {
+		ext["errors"] = errorChain(err)
+	}
+	return response.ErrorInfo{
+		Code:       response.ErrCodeInternalError,
+		Details:    fmt.Sprint(recovered),
+		Extensions: ext,
+	}
+}
+
+// errorChain lists err and every error it wraps, depth first
+func errorChain(err error) []string {
+	var chain []string
+	var walk func(error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		chain = append(chain, err.Error())
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		}
+	}
+	walk(err)
+	return chain
 }
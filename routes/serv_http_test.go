@@ -0,0 +1,174 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func serve(r *Router, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+// a static route answers 405 with Allow for the other methods, like a
+// dynamic one
+func TestMethodNotAllowed(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	r.Get("/ping", func(w http.ResponseWriter, req *http.Request) {})
+	r.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) {})
+	r.DELETE("/users/{id}", func(w http.ResponseWriter, req *http.Request) {})
+
+	tests := []struct {
+		method, path, allow string
+	}{
+		{http.MethodPost, "/ping", "GET, HEAD, OPTIONS"},
+		{http.MethodPut, "/users/1", "DELETE, GET, HEAD, OPTIONS"},
+	}
+	for _, tt := range tests {
+		rec := serve(r, tt.method, tt.path)
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s = %d, want 405", tt.method, tt.path, rec.Code)
+		}
+		if got := rec.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.path, got, tt.allow)
+		}
+	}
+
+	if rec := serve(r, http.MethodPost, "/missing"); rec.Code != http.StatusNotFound || rec.Header().Get("Allow") != "" {
+		t.Errorf("POST /missing = %d, Allow %q, want 404 without Allow", rec.Code, rec.Header().Get("Allow"))
+	}
+}
+
+// HEAD runs the GET handler, keeps its headers and status and drops the body
+func TestAutomaticHead(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	get := func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Method", req.Method)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("hello"))
+	}
+	r.Get("/ping", get)
+	r.Get("/users/{id}", get)
+
+	for _, path := range []string{"/ping", "/users/1"} {
+		rec := serve(r, http.MethodHead, path)
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("HEAD %s = %d, want 202", path, rec.Code)
+		}
+		if rec.Body.Len() != 0 {
+			t.Errorf("HEAD %s body = %q, want empty", path, rec.Body)
+		}
+		if rec.Header().Get("Content-Type") != "text/plain" || rec.Header().Get("X-Method") != http.MethodHead {
+			t.Errorf("HEAD %s headers = %v", path, rec.Header())
+		}
+		if rec := serve(r, http.MethodGet, path); rec.Body.String() != "hello" {
+			t.Errorf("GET %s body = %q", path, rec.Body)
+		}
+	}
+
+	// a registered HEAD handler wins
+	r.Handle([]string{http.MethodHead}, "/ping", func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	if rec := serve(r, http.MethodHead, "/ping"); rec.Code != http.StatusNoContent {
+		t.Errorf("HEAD /ping with its own handler = %d, want 204", rec.Code)
+	}
+}
+
+// countBuilds returns a middleware counting how many times the pipeline
+// wrapped it
+func countBuilds(builds *atomic.Int32) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		builds.Add(1)
+		return next
+	}
+}
+
+// concurrent first requests build the pipeline once, run with -race
+func TestBuildHandlerOnce(t *testing.T) {
+	var builds atomic.Int32
+	r := New()
+	r.AccessLog = false
+	r.Use(countBuilds(&builds))
+	r.Get("/ping", func(w http.ResponseWriter, req *http.Request) {})
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if rec := serve(r, http.MethodGet, "/ping"); rec.Code != http.StatusOK {
+				t.Errorf("status = %d", rec.Code)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if n := builds.Load(); n != 1 {
+		t.Fatalf("pipeline built %d times, want 1", n)
+	}
+
+	serve(r, http.MethodGet, "/ping")
+	if n := builds.Load(); n != 1 {
+		t.Fatalf("pipeline built %d times after another request, want 1", n)
+	}
+}
+
+// Use before Freeze rebuilds the pipeline on the next request, Use after
+// Freeze panics
+func TestUseAndFreeze(t *testing.T) {
+	var builds atomic.Int32
+	r := New()
+	r.AccessLog = false
+	r.Use(countBuilds(&builds))
+	r.Get("/ping", func(w http.ResponseWriter, req *http.Request) {})
+
+	serve(r, http.MethodGet, "/ping")
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Late", "1")
+			next.ServeHTTP(w, req)
+		})
+	})
+	if rec := serve(r, http.MethodGet, "/ping"); rec.Header().Get("X-Late") != "1" {
+		t.Error("middleware added after the first request is not used")
+	}
+	if n := builds.Load(); n != 2 {
+		t.Fatalf("pipeline built %d times, want 2", n)
+	}
+
+	r.Freeze()
+	if n := builds.Load(); n != 2 {
+		t.Fatalf("Freeze rebuilt a built pipeline, %d builds", n)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Use after Freeze did not panic")
+			}
+		}()
+		r.Use(countBuilds(&builds))
+	}()
+	serve(r, http.MethodGet, "/ping")
+	if n := builds.Load(); n != 2 {
+		t.Fatalf("pipeline built %d times after Freeze, want 2", n)
+	}
+}
+
+// Freeze builds the pipeline before the first request
+func TestFreezeBuildsNow(t *testing.T) {
+	var builds atomic.Int32
+	r := New()
+	r.Use(countBuilds(&builds))
+	r.Freeze()
+	if n := builds.Load(); n != 1 {
+		t.Fatalf("pipeline built %d times by Freeze, want 1", n)
+	}
+}
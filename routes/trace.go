@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/he-end/simproute/goruntime"
+	logger "github.com/he-end/simproute/route_logger"
+	"github.com/he-end/simproute/tracing"
+	"go.uber.org/zap"
+)
+
+// routeState is filled by dispatch so the outer pipeline stages know
+// which route served the request
+type routeState struct {
+	// matched pattern, e.g. "/users/{id}", empty on 404
+	pattern string
+}
+
+type routeStateKey struct{}
+
+func routeStateFrom(ctx context.Context) *routeState {
+	st, _ := ctx.Value(routeStateKey{}).(*routeState)
+	return st
+}
+
+// recorderOf reuses the recorder of an outer stage
+func recorderOf(w http.ResponseWriter) *responseRecorer {
+	if rec, ok := w.(*responseRecorer); ok {
+		return rec
+	}
+	return &responseRecorer{ResponseWriter: w, status: http.StatusOK}
+}
+
+// mwTrace records a server span per request, continuing the trace of an
+// inbound traceparent, and sends traceparent/tracestate back to the client.
+// The span of an inbound trace without the sampled bit is not exported.
+func mwTrace(tracer *tracing.Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			remote, _ := tracing.Extract(req.Header)
+			ctx, span := tracer.Start(req.Context(), req.Method, tracing.SpanKindServer, remote)
+			sc := span.SpanContext()
+
+			state := &routeState{}
+			ctx = context.WithValue(ctx, routeStateKey{}, state)
+			ctx = logger.WithFields(ctx,
+				zap.String("trace_id", sc.TraceID.String()),
+				zap.String("span_id", sc.SpanID.String()),
+			)
+
+			span.SetAttribute("http.request.method", req.Method)
+			span.SetAttribute("url.path", req.URL.Path)
+			if id := goruntime.FromContext(ctx); id != "" {
+				span.SetAttribute("request_id", id)
+			}
+			tracing.Inject(sc, w.Header())
+
+			rec := recorderOf(w)
+			finished := false
+			defer func() {
+				if state.pattern != "" {
+					span.SetName(req.Method + " " + state.pattern)
+					span.SetAttribute("http.route", state.pattern)
+				}
+				span.SetAttribute("http.response.status_code", rec.status)
+				switch {
+				case !finished:
+					span.SetStatus(tracing.StatusError, "panic")
+				case rec.status >= 500:
+					span.SetStatus(tracing.StatusError, http.StatusText(rec.status))
+				default:
+					span.SetStatus(tracing.StatusOK, "")
+				}
+				span.End()
+			}()
+			next.ServeHTTP(rec, req.WithContext(ctx))
+			finished = true
+		})
+	}
+}
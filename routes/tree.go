@@ -0,0 +1,261 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// node is one edge of the compressed radix tree used to match dynamic routes.
+//
+// Static text is stored compressed in prefix, parameters are stored as a
+// dedicated child so lookup can try static edges before parameter edges.
+type node struct {
+	// static label of this edge, empty for parameter nodes
+	prefix string
+
+	// first byte of every static child, same order as children
+	indices  string
+	children []*node
+
+	// parameter children, each matches one path segment
+	// constrained parameters are kept before the unconstrained one
+	params []*node
+
+	// constraint of a parameter node, nil accepts any value
+	constraint *constraint
+
+	// catch-all child, matches the rest of the path including slashes
+	catchAll *node
+
+	// leaf for the pattern ending on this node
+	leaf *leaf
+
+	// inline is set on parameter nodes when a static child does not start
+	// with '/' (e.g. "/files/:name.json"), so the parameter may end mid segment
+	inline bool
+}
+
+// leaf holds the handlers registered for one dynamic pattern.
+// methods is shared with the matching entry in Router.DynamicRoutes.
+type leaf struct {
+	pattern routePattern
+	methods map[string]http.Handler
+
+	// next pattern of the same shape with other parameter names
+	// (e.g. GET /users/:id and DELETE /users/:userID), tried in order
+	next *leaf
+}
+
+// insert stores lf below n for the pattern parts. lf is chained after the
+// leaves of patterns with the same shape, so each method keeps the parameter
+// names of its own pattern.
+func (n *node) insert(parts []patternPart, lf *leaf) {
+	for i, part := range parts {
+		switch part.kind {
+		case partStatic:
+			n = n.staticChild(part.text)
+		case partParam:
+			n = n.paramChild(part.constraint)
+			if i+1 < len(parts) && parts[i+1].kind == partStatic && parts[i+1].text[0] != '/' {
+				n.inline = true
+			}
+		case partCatchAll:
+			if n.catchAll == nil {
+				n.catchAll = &node{}
+			}
+			n = n.catchAll
+		}
+	}
+	if n.leaf == nil {
+		n.leaf = lf
+		return
+	}
+	last := n.leaf
+	for last.next != nil {
+		last = last.next
+	}
+	last.next = lf
+}
+
+// staticChild walks (and splits when needed) the static edges below n for s
+// and returns the node where s ends.
+func (n *node) staticChild(s string) *node {
+	for s != "" {
+		i := strings.IndexByte(n.indices, s[0])
+		if i < 0 {
+			child := &node{prefix: s}
+			n.indices += s[:1]
+			n.children = append(n.children, child)
+			return child
+		}
+
+		child := n.children[i]
+		l := commonPrefix(s, child.prefix)
+		if l < len(child.prefix) {
+			// split the edge, the tail keeps everything the child had
+			tail := &node{
+				prefix:   child.prefix[l:],
+				indices:  child.indices,
+				children: child.children,
+				params:   child.params,
+				catchAll: child.catchAll,
+				leaf:     child.leaf,
+				inline:   child.inline,
+			}
+			child.prefix = child.prefix[:l]
+			child.indices = tail.prefix[:1]
+			child.children = []*node{tail}
+			child.params = nil
+			child.catchAll = nil
+			child.leaf = nil
+			child.inline = false
+		}
+		n = child
+		s = s[l:]
+	}
+	return n
+}
+
+// paramChild returns the parameter child of n with the given constraint,
+// a new child is added after the other constrained ones
+func (n *node) paramChild(c *constraint) *node {
+	pos := len(n.params)
+	for i, p := range n.params {
+		if sameConstraint(p.constraint, c) {
+			return p
+		}
+		if p.constraint == nil {
+			pos = i
+		}
+	}
+	child := &node{constraint: c}
+	n.params = append(n.params, nil)
+	copy(n.params[pos+1:], n.params[pos:])
+	n.params[pos] = child
+	return child
+}
+
+func sameConstraint(a, b *constraint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.expr == b.expr
+}
+
+func commonPrefix(a, b string) int {
+	max := len(a)
+	if len(b) < max {
+		max = len(b)
+	}
+	i := 0
+	for i < max && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// find matches path below n (whose own prefix is already consumed).
+//
+// Static edges are tried before the parameter edges and the parameter edges
+// before the catch-all edge. The search backtracks, so the first leaf found
+// that has a handler for method is returned.
+// Leaves matching the path without the method are collected in allowed
+// so the caller can answer 405 or OPTIONS.
+func (n *node) find(path, method string, values []string, allowed *[]*leaf) (*leaf, []string) {
+	if path == "" {
+		if lf := n.leaf.match(method, allowed); lf != nil {
+			return lf, values
+		}
+	} else {
+		if i := strings.IndexByte(n.indices, path[0]); i >= 0 {
+			child := n.children[i]
+			if len(path) >= len(child.prefix) && path[:len(child.prefix)] == child.prefix {
+				if lf, v := child.find(path[len(child.prefix):], method, values, allowed); lf != nil {
+					return lf, v
+				}
+			}
+		}
+
+		end := -1
+		if len(n.params) > 0 {
+			if end = strings.IndexByte(path, '/'); end < 0 {
+				end = len(path)
+			}
+		}
+		if end > 0 {
+			for _, p := range n.params {
+				// inline text after the parameter is more specific than the
+				// parameter spanning the whole segment, longest value first
+				if p.inline {
+					for i := end - 1; i > 0; i-- {
+						if !p.accept(path[:i]) {
+							continue
+						}
+						if lf, v := p.find(path[i:], method, append(values, path[:i]), allowed); lf != nil {
+							return lf, v
+						}
+					}
+				}
+				if p.accept(path[:end]) {
+					if lf, v := p.find(path[end:], method, append(values, path[:end]), allowed); lf != nil {
+						return lf, v
+					}
+				}
+			}
+		}
+	}
+
+	// catch-all takes whatever is left, even an empty rest
+	if c := n.catchAll; c != nil {
+		if lf := c.leaf.match(method, allowed); lf != nil {
+			return lf, append(values, path)
+		}
+	}
+	return nil, values
+}
+
+// accept reports whether value passes the constraint of parameter node n
+func (n *node) accept(value string) bool {
+	return n.constraint == nil || n.constraint.match(value)
+}
+
+// match returns the first leaf of the chain with a handler for method,
+// otherwise the leaves are added to allowed and nil is returned
+func (lf *leaf) match(method string, allowed *[]*leaf) *leaf {
+	for l := lf; l != nil; l = l.next {
+		if _, ok := l.methods[method]; ok {
+			return l
+		}
+	}
+	for l := lf; l != nil; l = l.next {
+		*allowed = append(*allowed, l)
+	}
+	return nil
+}
+
+var paramValuesPool = sync.Pool{
+	New: func() any {
+		s := make([]string, 0, 8)
+		return &s
+	},
+}
+
+// lookup returns the leaf and parameters for path and method.
+// When no leaf has the method, allowed holds the leaves matching the path.
+func (n *node) lookup(path, method string) (lf *leaf, params map[string]string, allowed []*leaf) {
+	buf := paramValuesPool.Get().(*[]string)
+	lf, values := n.find(path, method, (*buf)[:0], &allowed)
+	if lf != nil && len(lf.pattern.paramNames) > 0 {
+		params = make(map[string]string, len(lf.pattern.paramNames))
+		for i, name := range lf.pattern.paramNames {
+			if i < len(values) {
+				params[name] = values[i]
+			}
+		}
+	}
+	clear(values)
+	*buf = values[:0]
+	paramValuesPool.Put(buf)
+	return lf, params, allowed
+}
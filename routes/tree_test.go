@@ -0,0 +1,209 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/he-end/simproute/routes/routeutil"
+)
+
+var okHandler = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+// buildTree inserts every pattern with a GET handler
+func buildTree(patterns ...string) *node {
+	root := &node{}
+	for _, p := range patterns {
+		rp := compilePattern(p)
+		root.insert(rp.parts, &leaf{pattern: rp, methods: map[string]http.Handler{http.MethodGet: okHandler}})
+	}
+	return root
+}
+
+func TestTreePrecedence(t *testing.T) {
+	root := buildTree(
+		"/users/me",
+		"/users/:id",
+		"/users/*rest",
+		"/users/:id/posts",
+		"/users/me/settings",
+		"/files/{name}.json",
+		"/files/{name}",
+		"/a/b/c",
+		"/a/:x/d",
+	)
+	tests := []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		// static > param > catch-all
+		{"/users/me", "/users/me", nil},
+		{"/users/42", "/users/:id", map[string]string{"id": "42"}},
+		{"/users/42/likes", "/users/*rest", map[string]string{"rest": "42/likes"}},
+		{"/users/", "/users/*rest", map[string]string{"rest": ""}},
+		{"/users/me/settings", "/users/me/settings", nil},
+		// static "me" has no /posts child, the search backtracks to :id
+		{"/users/me/posts", "/users/:id/posts", map[string]string{"id": "me"}},
+		// static "b" has no /d child, backtrack to :x
+		{"/a/b/d", "/a/:x/d", map[string]string{"x": "b"}},
+		{"/a/b/c", "/a/b/c", nil},
+		// inline text after a parameter
+		{"/files/report.json", "/files/{name}.json", map[string]string{"name": "report"}},
+		{"/files/report.csv", "/files/{name}", map[string]string{"name": "report.csv"}},
+		{"/nope", "", nil},
+		{"/a/b", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			lf, params, _ := root.lookup(tt.path, http.MethodGet)
+			got := ""
+			if lf != nil {
+				got = lf.pattern.pattern
+			}
+			if got != tt.pattern {
+				t.Fatalf("lookup(%q) matched %q, want %q", tt.path, got, tt.pattern)
+			}
+			if len(params) != 0 || len(tt.params) != 0 {
+				if !reflect.DeepEqual(map[string]string(params), tt.params) {
+					t.Fatalf("lookup(%q) params = %v, want %v", tt.path, params, tt.params)
+				}
+			}
+		})
+	}
+}
+
+func TestTreeAllowed(t *testing.T) {
+	root := buildTree("/users/:id")
+	lf, _, allowed := root.lookup("/users/1", http.MethodPost)
+	if lf != nil {
+		t.Fatalf("POST matched %q, want no match", lf.pattern.pattern)
+	}
+	if len(allowed) != 1 || allowed[0].pattern.pattern != "/users/:id" {
+		t.Fatalf("allowed = %v, want the /users/:id leaf", allowed)
+	}
+}
+
+// patterns of the same shape with other parameter names keep their own
+// methods and parameter names
+func TestSameShapeOtherMethods(t *testing.T) {
+	r := New(WithConflictPolicy(ConflictIgnore))
+	r.AccessLog = false
+	var got string
+	r.Get("/users/:id", func(w http.ResponseWriter, req *http.Request) {
+		got = "GET " + routeutil.GetRouteParams(req.Context())["id"]
+	})
+	r.DELETE("/users/:userID", func(w http.ResponseWriter, req *http.Request) {
+		got = "DELETE " + routeutil.GetRouteParams(req.Context())["userID"]
+	})
+
+	for _, tt := range []struct{ method, want string }{
+		{http.MethodGet, "GET 7"},
+		{http.MethodDelete, "DELETE 7"},
+	} {
+		got = ""
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tt.method, "/users/7", nil))
+		if rec.Code != http.StatusOK || got != tt.want {
+			t.Errorf("%s /users/7: status %d, handler %q, want 200 and %q", tt.method, rec.Code, got, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/users/7", nil))
+	if allow := rec.Header().Get("Allow"); rec.Code != http.StatusMethodNotAllowed || allow != "DELETE, GET, HEAD, OPTIONS" {
+		t.Errorf("PUT /users/7: status %d, Allow %q", rec.Code, allow)
+	}
+}
+
+// benchPatterns returns n REST like patterns and a path matching the last one
+func benchPatterns(n int) ([]string, string) {
+	patterns := make([]string, 0, n)
+	for i := 0; len(patterns) < n; i++ {
+		patterns = append(patterns,
+			fmt.Sprintf("/api/v1/res%d/:id", i),
+			fmt.Sprintf("/api/v1/res%d/:id/items/{item}", i),
+			fmt.Sprintf("/api/v1/res%d/:id/items/{item}/tags", i),
+		)
+	}
+	patterns = patterns[:n]
+	last := patterns[n-1]
+	path := strings.NewReplacer(":id", "42", "{item}", "7").Replace(last)
+	return patterns, path
+}
+
+// legacyPattern is the regex matching of dynamic routes before the tree
+type legacyPattern struct {
+	regex      *regexp.Regexp
+	paramNames []string
+}
+
+func compileLegacy(pattern string) legacyPattern {
+	regexPattern := pattern
+	var paramNames []string
+	syntaxParam := `([^/]+)`
+	for _, m := range regexp.MustCompile(`:([a-zA-Z_][a-zA-Z0-9]*)`).FindAllStringSubmatch(pattern, -1) {
+		paramNames = append(paramNames, m[1])
+		regexPattern = strings.ReplaceAll(regexPattern, m[0], syntaxParam)
+	}
+	for _, m := range regexp.MustCompile(`\{([a-zA-Z][a-zA-Z0-9]*)\}`).FindAllStringSubmatch(pattern, -1) {
+		paramNames = append(paramNames, m[1])
+		regexPattern = strings.ReplaceAll(regexPattern, m[0], syntaxParam)
+	}
+	regexPattern = regexp.QuoteMeta(regexPattern)
+	regexPattern = strings.ReplaceAll(regexPattern, `\(\[\^/\]\+\)`, syntaxParam)
+	return legacyPattern{regex: regexp.MustCompile("^" + regexPattern + "$"), paramNames: paramNames}
+}
+
+// legacyLookup is the linear regex scan the router used before the tree
+func legacyLookup(routes []legacyPattern, path string) map[string]string {
+	for _, rt := range routes {
+		if matches := rt.regex.FindStringSubmatch(path); matches != nil {
+			params := make(map[string]string)
+			for i, name := range rt.paramNames {
+				if i+1 < len(matches) {
+					params[name] = matches[i+1]
+				}
+			}
+			return params
+		}
+	}
+	return nil
+}
+
+func BenchmarkLookup(b *testing.B) {
+	for _, n := range []int{10, 100, 300} {
+		patterns, path := benchPatterns(n)
+
+		b.Run(fmt.Sprintf("tree/%d", n), func(b *testing.B) {
+			root := buildTree(patterns...)
+			if lf, _, _ := root.lookup(path, http.MethodGet); lf == nil {
+				b.Fatalf("%s not matched", path)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				root.lookup(path, http.MethodGet)
+			}
+		})
+
+		b.Run(fmt.Sprintf("regex/%d", n), func(b *testing.B) {
+			legacy := make([]legacyPattern, len(patterns))
+			for i, p := range patterns {
+				legacy[i] = compileLegacy(p)
+			}
+			if legacyLookup(legacy, path) == nil {
+				b.Fatalf("%s not matched", path)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				legacyLookup(legacy, path)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/he-end/simproute/routes/binding"
+	"github.com/he-end/simproute/routes/response"
+	"github.com/he-end/simproute/routes/validate"
+)
+
+// StatusCoder can be implemented by the response of a typed handler to
+// answer another status than 200 (201, 202 or 204)
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Typed adapts fn to a HandlerFunc. A struct Req, or a pointer to one, is
+// filled with binding.Bind and checked with validate.Struct, the returned
+// Resp is sent as data of the response envelope. Errors are answered with
+// WriteError. An unknown validate rule on Req panics here, at registration.
+//
+// Example:
+//
+//	r.POST("/users", routes.Typed(func(ctx context.Context, req CreateUser) (User, error) {
+//		return svc.Create(ctx, req)
+//	}))
+func Typed[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) HandlerFunc {
+	t := reflect.TypeOf((*Req)(nil)).Elem()
+	ptr := t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct
+	bind := t.Kind() == reflect.Struct || ptr
+	if bind {
+		if err := validate.Prepare(t); err != nil {
+			panic(err)
+		}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if bind {
+			// a pointer Req gets a new struct, a struct Req is filled in place
+			var dst any = &req
+			if ptr {
+				dst = reflect.New(t.Elem()).Interface()
+				req = dst.(Req)
+			}
+			if err := binding.Bind(r, dst); err != nil {
+				WriteError(w, r, err)
+				return
+			}
+			if err := validate.Struct(dst); err != nil {
+				WriteError(w, r, err)
+				return
+			}
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+		writeData(w, r, resp)
+	}
+}
+
+// HandleTyped registers Typed(fn) and documents the route with the
+// Req body and the Resp response
+func HandleTyped[Req, Resp any](r *Router, method []string, path string, fn func(ctx context.Context, req Req) (Resp, error), mws ...func(http.Handler) http.Handler) *Route {
+	var req Req
+	var resp Resp
+	status := http.StatusOK
+	if sc, ok := any(resp).(StatusCoder); ok {
+		status = sc.StatusCode()
+	}
+	rt := r.Handle(method, path, Typed(fn), mws...)
+	if status == http.StatusNoContent {
+		return rt.Body(req).Response(status, nil)
+	}
+	return rt.Body(req).Response(status, resp)
+}
+
+func writeData(w http.ResponseWriter, r *http.Request, data any) {
+	status := http.StatusOK
+	if sc, ok := data.(StatusCoder); ok {
+		status = sc.StatusCode()
+	}
+	rh := response.For(r)
+	switch status {
+	case http.StatusCreated:
+		rh.Created(w, "", http.StatusText(status), data)
+	case http.StatusAccepted:
+		rh.Accepted(w, http.StatusText(status), data)
+	case http.StatusNoContent:
+		rh.SuccessNoContent(w, http.StatusText(status))
+	default:
+		rh.Success(w, http.StatusText(http.StatusOK), data)
+	}
+}
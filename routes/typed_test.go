@@ -0,0 +1,74 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type typedReq struct {
+	ID   int64  `path:"id"`
+	Name string `json:"name" validate:"required,min=3"`
+}
+
+type typedResp struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestTypedBinds(t *testing.T) {
+	r := New()
+	r.AccessLog = false
+	HandleTyped(r, []string{http.MethodPost}, "/v/:id", func(ctx context.Context, req typedReq) (typedResp, error) {
+		return typedResp{ID: req.ID, Name: req.Name}, nil
+	})
+	HandleTyped(r, []string{http.MethodPost}, "/p/:id", func(ctx context.Context, req *typedReq) (typedResp, error) {
+		if req == nil {
+			t.Error("pointer Req is nil")
+			return typedResp{}, nil
+		}
+		return typedResp{ID: req.ID, Name: req.Name}, nil
+	})
+
+	for _, path := range []string{"/v/7", "/p/7"} {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"name":"alice"}`)))
+			var body struct {
+				Data typedResp `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Code != http.StatusOK {
+				t.Fatalf("status %d body %s", rec.Code, rec.Body)
+			}
+			if body.Data != (typedResp{ID: 7, Name: "alice"}) {
+				t.Errorf("data = %+v", body.Data)
+			}
+
+			// the pointer Req is validated too
+			rec = httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"name":"al"}`)))
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"rule":"min"`) {
+				t.Errorf("invalid name: status %d body %s", rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+// an unknown rule fails when the route is registered, not on the first request
+func TestTypedUnknownRule(t *testing.T) {
+	type inner struct {
+		Code string `json:"code" validate:"nosuchrule"`
+	}
+	type badReq struct {
+		Items []inner `json:"items"`
+	}
+	defer func() {
+		if err := recover(); err == nil || !strings.Contains(err.(error).Error(), "nosuchrule") {
+			t.Fatalf("recovered %v, want the unknown rule error", err)
+		}
+	}()
+	Typed(func(ctx context.Context, req *badReq) (struct{}, error) { return struct{}{}, nil })
+}
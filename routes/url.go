@@ -0,0 +1,120 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrUnknownRoute is returned by URL for a name without a route
+	ErrUnknownRoute = errors.New("routes: unknown route name")
+	// ErrMissingParam is returned by URL when a parameter of the pattern has no value
+	ErrMissingParam = errors.New("routes: missing route parameter")
+	// ErrInvalidParam is returned by URL when a value does not pass the constraint
+	ErrInvalidParam = errors.New("routes: invalid route parameter")
+)
+
+// Route is a registered route, returned by Handle and the method helpers
+type Route struct {
+	rt *route
+}
+
+// Name sets the name used by Router.URL, a name can be used once per Router
+//
+// Example:
+//
+//	r.Get("/users/{id:int}", GetUserHandler).Name("user")
+func (rt *Route) Name(name string) *Route {
+	owner := rt.rt.owner
+	if owner == nil {
+		// Group route, the name is added when the group is merged
+		rt.rt.name = name
+		return rt
+	}
+
+	owner.MU.Lock()
+	defer owner.MU.Unlock()
+	rt.rt.name = name
+	owner.addName(rt.rt)
+	return rt
+}
+
+// addName indexes rt by its name, r.MU must be held
+func (r *Router) addName(rt *route) {
+	if r.names == nil {
+		r.names = make(map[string]*route)
+	}
+	if prev, ok := r.names[rt.name]; ok && prev != rt {
+		panic("routes: route name " + rt.name + " is already used by " + prev.pattern)
+	}
+	r.names[rt.name] = rt
+}
+
+// URL builds the path of the route registered with name.
+// pairs are key, value; keys matching a parameter fill the pattern (escaped),
+// the others are added as query values.
+//
+// Example:
+//
+//	u, err := r.URL("user", "id", "42", "tab", "posts")
+//	// u = "/users/42?tab=posts"
+func (r *Router) URL(name string, pairs ...string) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("routes: url %q: odd number of key/value pairs", name)
+	}
+	r = r.top()
+
+	r.MU.RLock()
+	rt := r.names[name]
+	r.MU.RUnlock()
+	if rt == nil {
+		return "", fmt.Errorf("%w: %q", ErrUnknownRoute, name)
+	}
+
+	values := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		values[pairs[i]] = pairs[i+1]
+	}
+
+	var b strings.Builder
+	for _, part := range compilePattern(rt.pattern).parts {
+		if part.kind == partStatic {
+			b.WriteString(part.text)
+			continue
+		}
+
+		v, ok := values[part.text]
+		if !ok {
+			return "", fmt.Errorf("%w: %q of route %q", ErrMissingParam, part.text, name)
+		}
+		delete(values, part.text)
+
+		if part.kind == partCatchAll {
+			// keep the slashes of the rest, escape each segment
+			segs := strings.Split(v, "/")
+			for i, seg := range segs {
+				segs[i] = url.PathEscape(seg)
+			}
+			b.WriteString(strings.Join(segs, "/"))
+			continue
+		}
+		if v == "" || strings.Contains(v, "/") || (part.constraint != nil && !part.constraint.match(v)) {
+			return "", fmt.Errorf("%w: %q=%q of route %q", ErrInvalidParam, part.text, v, name)
+		}
+		b.WriteString(url.PathEscape(v))
+	}
+
+	if len(values) > 0 {
+		query := make(url.Values, len(values))
+		for i := 0; i < len(pairs); i += 2 {
+			if _, ok := values[pairs[i]]; ok {
+				query.Set(pairs[i], pairs[i+1])
+			}
+		}
+		b.WriteByte('?')
+		b.WriteString(query.Encode())
+	}
+	return b.String(), nil
+}
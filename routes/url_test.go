@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	r := New()
+	h := func(w http.ResponseWriter, req *http.Request) {}
+	r.Get("/users/{id:int}", h).Name("user")
+	r.Get("/users/:id/posts/{slug}", h).Name("post")
+	r.Get("/static/*filepath", h).Name("static")
+	r.Get("/about", h).Name("about")
+	r.Group("/api/v1", func(gr *Router) {
+		gr.Get("/items/:id", h).Name("item")
+	})
+
+	tests := []struct {
+		name  string
+		route string
+		pairs []string
+		want  string
+		err   error
+	}{
+		{"static", "about", nil, "/about", nil},
+		{"param", "user", []string{"id", "42"}, "/users/42", nil},
+		{"two params", "post", []string{"id", "7", "slug", "hello-world"}, "/users/7/posts/hello-world", nil},
+		{"group route", "item", []string{"id", "9"}, "/api/v1/items/9", nil},
+		{"escaped param", "post", []string{"id", "a b", "slug", "x?y#z"}, "/users/a%20b/posts/x%3Fy%23z", nil},
+		{"catch-all keeps slashes", "static", []string{"filepath", "css/my app.css"}, "/static/css/my%20app.css", nil},
+		{"query", "user", []string{"id", "1", "tab", "posts", "q", "a&b=c"}, "/users/1?q=a%26b%3Dc&tab=posts", nil},
+		{"query only", "about", []string{"lang", "ja"}, "/about?lang=ja", nil},
+		{"missing param", "post", []string{"id", "7"}, "", ErrMissingParam},
+		{"constraint", "user", []string{"id", "abc"}, "", ErrInvalidParam},
+		{"slash in param", "post", []string{"id", "1/2", "slug", "x"}, "", ErrInvalidParam},
+		{"empty param", "post", []string{"id", "", "slug", "x"}, "", ErrInvalidParam},
+		{"unknown name", "nope", nil, "", ErrUnknownRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.URL(tt.route, tt.pairs...)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("URL(%q, %v) error = %v, want %v", tt.route, tt.pairs, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("URL(%q, %v) = %q, want %q", tt.route, tt.pairs, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := r.URL("user", "id"); err == nil {
+		t.Error("odd number of pairs gave no error")
+	}
+}
+
+func TestURLDuplicateName(t *testing.T) {
+	r := New()
+	h := func(w http.ResponseWriter, req *http.Request) {}
+	r.Get("/a", h).Name("x")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicate route name did not panic")
+		}
+	}()
+	r.Get("/b", h).Name("x")
+}
@@ -0,0 +1,204 @@
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	rulesMU sync.RWMutex
+	// built-in rules, used as validate:"required,min=3"
+	rules = map[string]rule{
+		"required":         {hasValue, "{field} is required"},
+		"required_with":    {requiredWith, "{field} is required when {param} is set"},
+		"required_without": {requiredWithout, "{field} is required when {param} is not set"},
+
+		"min": {sizeRule(func(size, n float64) bool { return size >= n }), "{field} must be at least {param}"},
+		"max": {sizeRule(func(size, n float64) bool { return size <= n }), "{field} must be at most {param}"},
+		"len": {sizeRule(func(size, n float64) bool { return size == n }), "{field} must be exactly {param}"},
+		"gt":  {sizeRule(func(size, n float64) bool { return size > n }), "{field} must be greater than {param}"},
+		"gte": {sizeRule(func(size, n float64) bool { return size >= n }), "{field} must be at least {param}"},
+		"lt":  {sizeRule(func(size, n float64) bool { return size < n }), "{field} must be less than {param}"},
+		"lte": {sizeRule(func(size, n float64) bool { return size <= n }), "{field} must be at most {param}"},
+
+		"oneof": {oneOf, "{field} must be one of {param}"},
+
+		"email":    {stringRule(isEmail), "{field} must be a valid email address"},
+		"url":      {stringRule(isURL), "{field} must be a valid URL"},
+		"uuid":     {stringRule(isUUID), "{field} must be a valid UUID"},
+		"alpha":    {stringRule(allRunes(unicode.IsLetter)), "{field} must contain only letters"},
+		"alphanum": {stringRule(allRunes(isLetterOrDigit)), "{field} must contain only letters and digits"},
+		"numeric":  {stringRule(isNumeric), "{field} must be a number"},
+
+		// cross-field rules, param is the Go name of a field of the same struct
+		"eqfield":  {fieldRule(func(c int) bool { return c == 0 }), "{field} must be equal to {param}"},
+		"nefield":  {fieldRule(func(c int) bool { return c != 0 }), "{field} must be different from {param}"},
+		"gtfield":  {fieldRule(func(c int) bool { return c > 0 }), "{field} must be greater than {param}"},
+		"gtefield": {fieldRule(func(c int) bool { return c >= 0 }), "{field} must be greater than or equal to {param}"},
+		"ltfield":  {fieldRule(func(c int) bool { return c < 0 }), "{field} must be less than {param}"},
+		"ltefield": {fieldRule(func(c int) bool { return c <= 0 }), "{field} must be less than or equal to {param}"},
+	}
+)
+
+// hasValue reports whether the field is set: not nil, not empty and not zero
+func hasValue(f Field) bool {
+	return present(f.Value)
+}
+
+func present(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return false
+	case reflect.Pointer, reflect.Interface:
+		return !v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() > 0
+	}
+	return !v.IsZero()
+}
+
+func requiredWith(f Field) bool {
+	return !present(sibling(f)) || present(f.Value)
+}
+
+func requiredWithout(f Field) bool {
+	return present(sibling(f)) || present(f.Value)
+}
+
+// sibling returns the field named by f.Param in the same struct
+func sibling(f Field) reflect.Value {
+	v := f.Parent.FieldByName(f.Param)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// size is the length of strings (in characters), slices and maps,
+// or the value of numbers
+func size(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func sizeRule(cmp func(size, n float64) bool) RuleFunc {
+	return func(f Field) bool {
+		n, err := strconv.ParseFloat(f.Param, 64)
+		if err != nil {
+			return false
+		}
+		s, ok := size(f.Value)
+		return ok && cmp(s, n)
+	}
+}
+
+func oneOf(f Field) bool {
+	var s string
+	switch f.Value.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = fmt.Sprint(f.Value.Interface())
+	default:
+		return false
+	}
+	for _, allowed := range strings.Fields(f.Param) {
+		if s == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func stringRule(fn func(string) bool) RuleFunc {
+	return func(f Field) bool {
+		return f.Value.Kind() == reflect.String && fn(f.Value.String())
+	}
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && strings.Contains(s[strings.LastIndexByte(s, '@'):], ".")
+}
+
+func isURL(s string) bool {
+	u, err := url.ParseRequestURI(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func isUUID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil && len(s) == 36
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func isLetterOrDigit(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func allRunes(fn func(rune) bool) func(string) bool {
+	return func(s string) bool {
+		for _, r := range s {
+			if !fn(r) {
+				return false
+			}
+		}
+		return s != ""
+	}
+}
+
+func fieldRule(ok func(c int) bool) RuleFunc {
+	return func(f Field) bool {
+		c, comparable := compare(f.Value, sibling(f))
+		return comparable && ok(c)
+	}
+}
+
+// compare orders two numbers, strings or times
+func compare(a, b reflect.Value) (int, bool) {
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+	if a.Type() == timeType && b.IsValid() && b.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), true
+	}
+	if a.Kind() == reflect.String || b.Kind() == reflect.String {
+		return 0, false
+	}
+	x, okA := size(a)
+	y, okB := size(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	}
+	return 0, true
+}
@@ -0,0 +1,312 @@
+// Package validate checks struct fields against the rules of their
+// `validate` tag and reports every failing field
+//
+//	type Signup struct {
+//		Name     string `json:"name" validate:"required,min=3"`
+//		Email    string `json:"email" validate:"required,email"`
+//		Plan     string `json:"plan" validate:"oneof=free pro"`
+//		Password string `json:"password" validate:"required,min=8"`
+//		Confirm  string `json:"confirm" validate:"eqfield=Password"`
+//	}
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/he-end/simproute/routes/response"
+)
+
+// Errors lists the fields failing their rules, routes.WriteError(w, r, err)
+// answers it as 400 VALIDATION_ERROR listing the fields
+type Errors []response.FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Field is the field checked by a rule
+type Field struct {
+	// value of the field, pointers are dereferenced
+	Value reflect.Value
+	// text after "=" in the tag, e.g. "3" for min=3
+	Param string
+	// struct holding the field, used by cross-field rules
+	Parent reflect.Value
+}
+
+// RuleFunc reports whether the field passes the rule
+type RuleFunc func(f Field) bool
+
+type rule struct {
+	fn RuleFunc
+	// message template, {field} and {param} are replaced
+	message string
+}
+
+// RegisterRule adds a rule usable in validate tags, it replaces a built-in
+// rule with the same name. message may use {field} and {param}, it is sent
+// as is since the catalog only knows the built-in messages.
+//
+// Example:
+//
+//	validate.RegisterRule("slug", func(f validate.Field) bool {
+//		return slugRe.MatchString(f.Value.String())
+//	}, "{field} must be a slug")
+func RegisterRule(name string, fn RuleFunc, message string) {
+	rulesMU.Lock()
+	rules[name] = rule{fn: fn, message: message}
+	customRules[name] = true
+	rulesMU.Unlock()
+}
+
+// Struct checks v, a struct or a pointer to a struct, nested structs and
+// slices of structs included. It returns Errors or nil.
+func Struct(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: Struct called with %T", v))
+	}
+
+	var errs Errors
+	checkStruct(rv, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// customRules are the rules added or replaced by RegisterRule
+var customRules = map[string]bool{}
+
+// fieldParamRules are the built-in rules whose param is the Go name of a
+// field of the same struct
+var fieldParamRules = map[string]bool{
+	"required_with": true, "required_without": true,
+	"eqfield": true, "nefield": true, "gtfield": true, "gtefield": true, "ltfield": true, "ltefield": true,
+}
+
+// tagRule is one parsed rule of a validate tag
+type tagRule struct {
+	name  string
+	param string
+	rule  rule
+	// catalog key of the built-in message, "validate.<rule>", empty for
+	// the rules of RegisterRule
+	key string
+}
+
+// fieldRules are the rules of one struct field
+type fieldRules struct {
+	index int
+	// json name, or the Go name without json tag
+	name      string
+	rules     []tagRule
+	omitempty bool
+}
+
+var fieldsCache sync.Map // reflect.Type -> []fieldRules
+
+// fieldsOf returns the cached rules of the struct type t, it panics on an
+// unknown rule or field name
+func fieldsOf(t reflect.Type) []fieldRules {
+	fields, err := parseFields(t)
+	if err != nil {
+		panic(err.Error())
+	}
+	return fields
+}
+
+// parseFields parses the validate tags of the struct type t and caches them
+func parseFields(t reflect.Type) ([]fieldRules, error) {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.([]fieldRules), nil
+	}
+
+	var fields []fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		f := fieldRules{index: i, name: sf.Name}
+		if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+			f.name = name
+		}
+		if sf.Anonymous {
+			// promoted fields keep the path of the outer struct
+			f.name = ""
+		}
+
+		for _, text := range strings.Split(sf.Tag.Get("validate"), ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(text), "=")
+			switch name {
+			case "":
+				continue
+			case "omitempty":
+				f.omitempty = true
+				continue
+			}
+			rulesMU.RLock()
+			r, ok := rules[name]
+			custom := customRules[name]
+			rulesMU.RUnlock()
+			if !ok {
+				return nil, fmt.Errorf("validate: unknown rule %s on %s.%s", strconv.Quote(name), t.Name(), sf.Name)
+			}
+			tr := tagRule{name: name, param: param, rule: r}
+			if !custom {
+				tr.key = "validate." + name
+				if fieldParamRules[name] {
+					if _, found := t.FieldByName(param); !found {
+						return nil, fmt.Errorf("validate: rule %s on %s.%s names the unknown field %s", name, t.Name(), sf.Name, strconv.Quote(param))
+					}
+				}
+			}
+			f.rules = append(f.rules, tr)
+		}
+		fields = append(fields, f)
+	}
+	fieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+// Prepare parses the validate tags of t and of the structs inside it, so an
+// unknown rule or a cross-field rule naming an unknown field is reported at
+// startup instead of panicking in the first Struct call. t may be a pointer
+// to a struct.
+//
+// Example:
+//
+//	if err := validate.Prepare(reflect.TypeOf(Signup{})); err != nil {
+//		log.Fatal(err)
+//	}
+func Prepare(t reflect.Type) error {
+	return prepare(t, make(map[reflect.Type]bool))
+}
+
+func prepare(t reflect.Type, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || seen[t] {
+		return nil
+	}
+	seen[t] = true
+	fields, err := parseFields(t)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if err := prepare(t.Field(f.index).Type, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkStruct(v reflect.Value, prefix string, errs *Errors) {
+	for _, f := range fieldsOf(v.Type()) {
+		fv := v.Field(f.index)
+		path := f.name
+		if prefix != "" && path != "" {
+			path = prefix + "." + path
+		} else if path == "" {
+			path = prefix
+		}
+
+		if f.omitempty && fv.IsZero() {
+			continue
+		}
+		value := fv
+		for value.Kind() == reflect.Pointer && !value.IsNil() {
+			value = value.Elem()
+		}
+
+		failed := false
+		for _, tr := range f.rules {
+			// only required and the required_* rules check a nil pointer
+			if value.Kind() == reflect.Pointer && !strings.HasPrefix(tr.name, "required") {
+				continue
+			}
+			if tr.rule.fn(Field{Value: value, Param: tr.param, Parent: v}) {
+				continue
+			}
+			msg, key := message(tr, path, value)
+			*errs = append(*errs, response.FieldError{
+				Field:      path,
+				Rule:       tr.name,
+				Param:      tr.param,
+				Message:    msg,
+				MessageKey: key,
+			})
+			failed = true
+			break
+		}
+		if !failed {
+			dive(value, path, errs)
+		}
+	}
+}
+
+// dive checks the structs inside v
+func dive(v reflect.Value, path string, errs *Errors) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() != timeType {
+			checkStruct(v, path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			for item.Kind() == reflect.Pointer && !item.IsNil() {
+				item = item.Elem()
+			}
+			if item.Kind() == reflect.Struct {
+				dive(item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// message returns the message of the failed rule and its catalog key, size
+// rules say "characters" for strings and "items" for collections, with the
+// ".string" and ".items" keys
+func message(tr tagRule, field string, v reflect.Value) (msg, key string) {
+	msg, key = tr.rule.message, tr.key
+	unit := ""
+	switch tr.name {
+	case "min", "max", "len", "gt", "gte", "lt", "lte":
+		switch v.Kind() {
+		case reflect.String:
+			msg += " characters"
+			unit = ".string"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			msg += " items"
+			unit = ".items"
+		}
+	}
+	if key != "" {
+		key += unit
+	}
+	if field == "" {
+		field = "value"
+	}
+	return strings.NewReplacer("{field}", field, "{param}", tr.param).Replace(msg), key
+}
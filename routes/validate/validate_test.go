@@ -0,0 +1,124 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/he-end/simproute/routes/i18n"
+)
+
+type signup struct {
+	Name  string   `json:"name" validate:"required,min=3"`
+	Email string   `json:"email" validate:"required,email"`
+	Tags  []string `json:"tags" validate:"max=2"`
+	Age   int      `json:"age" validate:"gte=18"`
+	Plan  string   `json:"plan" validate:"omitempty,slug"`
+}
+
+func TestStructMessages(t *testing.T) {
+	RegisterRule("slug", func(f Field) bool { return !strings.Contains(f.Value.String(), " ") }, "{field} must be a slug")
+
+	err := Struct(signup{Name: "ab", Email: "nope", Tags: []string{"a", "b", "c"}, Age: 16, Plan: "free plan"})
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Struct = %v, want Errors", err)
+	}
+	want := []struct{ field, message, key string }{
+		{"name", "name must be at least 3 characters", "validate.min.string"},
+		{"email", "email must be a valid email address", "validate.email"},
+		{"tags", "tags must be at most 2 items", "validate.max.items"},
+		{"age", "age must be at least 18", "validate.gte"},
+		// RegisterRule messages have no catalog key
+		{"plan", "plan must be a slug", ""},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("errors = %+v", errs)
+	}
+	for i, w := range want {
+		fe := errs[i]
+		if fe.Field != w.field || fe.Message != w.message || fe.MessageKey != w.key {
+			t.Errorf("error %d = %+v, want %+v", i, fe, w)
+		}
+	}
+}
+
+// every built-in message is in the default catalog, the English text is
+// the one Struct writes
+func TestCatalogHasRules(t *testing.T) {
+	c := i18n.Default()
+	rulesMU.RLock()
+	defer rulesMU.RUnlock()
+	for name, r := range rules {
+		if customRules[name] {
+			continue
+		}
+		keys := map[string]string{"validate." + name: r.message}
+		switch name {
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			keys["validate."+name+".string"] = r.message + " characters"
+			keys["validate."+name+".items"] = r.message + " items"
+		}
+		for key, english := range keys {
+			for _, locale := range []string{"en", "id", "ja"} {
+				text, _, ok := c.Lookup([]string{locale}, key, nil)
+				if !ok {
+					t.Errorf("%s missing in %s", key, locale)
+					continue
+				}
+				if locale == "en" && text != english {
+					t.Errorf("%s en = %q, validate writes %q", key, text, english)
+				}
+			}
+		}
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	type address struct {
+		City string `validate:"required"`
+		Zip  string `validate:"required_with=Cityy"`
+	}
+	type order struct {
+		Start time.Time
+		End   time.Time  `validate:"gtfield=Start"`
+		Ship  []*address `validate:"omitempty"`
+	}
+	type badRule struct {
+		Name string `validate:"requird"`
+	}
+	type badField struct {
+		Password string
+		Confirm  string `validate:"eqfield=Missing"`
+	}
+
+	tests := []struct {
+		name string
+		v    any
+		err  string
+	}{
+		{"valid", signup{}, ""},
+		{"pointer", &signup{}, ""},
+		{"unknown rule", badRule{}, `unknown rule "requird" on badRule.Name`},
+		{"unknown field", badField{}, `rule eqfield on badField.Confirm names the unknown field "Missing"`},
+		// the structs inside slices are prepared too
+		{"nested unknown field", order{}, `rule required_with on address.Zip names the unknown field "Cityy"`},
+	}
+	for _, tt := range tests {
+		err := Prepare(reflect.TypeOf(tt.v))
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("Prepare(%s) = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+
+	// Struct panics with the same message
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "unknown field") {
+			t.Errorf("Struct recovered %v, want the unknown field panic", r)
+		}
+	}()
+	Struct(badField{})
+}
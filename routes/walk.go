@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteInfo describes one method of a registered route
+type RouteInfo struct {
+	Method  string
+	Pattern string
+	// set with Route.Name, empty when not named
+	Name string
+	// pattern with every parameter written as {name}, e.g. "/users/{id}"
+	Template   string
+	ParamNames []string
+	// constraint expr per parameter (e.g. "int" or "[a-z-]+"), "" when
+	// unconstrained, same order as ParamNames
+	ParamConstraints []string
+	// true for a catch-all parameter, same order as ParamNames
+	ParamCatchAll []bool
+	// func names of the middleware wrapping the route, outer-most first,
+	// the root middleware included
+	Middlewares []string
+	// func name of the handler
+	Handler string
+	// documentation set with the Route methods
+	Operation Operation
+}
+
+// Walk calls fn for every registered route and method, in registration order.
+// The automatic HEAD and OPTIONS answers are not listed.
+// Walk stops on the first error returned by fn and returns it.
+func (r *Router) Walk(fn func(RouteInfo) error) error {
+	r = r.top()
+
+	r.MU.RLock()
+	routes := append([]*route(nil), r.routes...)
+	ops := make([]Operation, len(routes))
+	for i, rt := range routes {
+		ops[i] = rt.op
+	}
+	mws := make([]string, 0, len(r.Mws))
+	for _, mw := range r.Mws {
+		mws = append(mws, funcName(mw))
+	}
+	r.MU.RUnlock()
+
+	for i, rt := range routes {
+		routeMws := append([]string(nil), mws...)
+		for _, mw := range rt.mws {
+			routeMws = append(routeMws, funcName(mw))
+		}
+
+		var template strings.Builder
+		var cons []string
+		var catchAll []bool
+		pattern := compilePattern(rt.pattern)
+		for _, part := range pattern.parts {
+			if part.kind == partStatic {
+				template.WriteString(part.text)
+				continue
+			}
+			template.WriteString("{" + part.text + "}")
+			if part.constraint != nil {
+				cons = append(cons, part.constraint.expr)
+			} else {
+				cons = append(cons, "")
+			}
+			catchAll = append(catchAll, part.kind == partCatchAll)
+		}
+
+		for _, method := range rt.methods {
+			err := fn(RouteInfo{
+				Method:           method,
+				Pattern:          rt.pattern,
+				Name:             rt.name,
+				Template:         template.String(),
+				ParamNames:       append([]string(nil), pattern.paramNames...),
+				ParamConstraints: append([]string(nil), cons...),
+				ParamCatchAll:    append([]bool(nil), catchAll...),
+				Middlewares:      append([]string(nil), routeMws...),
+				Handler:          funcName(rt.handler),
+				Operation:        ops[i],
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// PrintRoutes writes the registered routes as a table to w,
+// func names are shown without their import path
+//
+// Example:
+//
+//	METHOD  PATTERN          NAME  HANDLER           MIDDLEWARE
+//	GET     /users/{id:int}  user  main.GetUser      main.Auth
+func (r *Router) PrintRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATTERN\tNAME\tHANDLER\tMIDDLEWARE")
+	err := r.Walk(func(ri RouteInfo) error {
+		mws := make([]string, len(ri.Middlewares))
+		for i, mw := range ri.Middlewares {
+			mws[i] = shortFuncName(mw)
+		}
+		_, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			ri.Method, ri.Pattern, ri.Name, shortFuncName(ri.Handler), strings.Join(mws, ", "))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+// funcName returns the name of the func fn, "" when fn is nil
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	// method values are named "pkg.(*T).Method-fm"
+	return strings.TrimSuffix(f.Name(), "-fm")
+}
+
+// shortFuncName strips the import path, "github.com/a/b/pkg.Fn" -> "pkg.Fn"
+func shortFuncName(name string) string {
+	return name[strings.LastIndexByte(name, '/')+1:]
+}
@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func listUsers(w http.ResponseWriter, req *http.Request) {}
+func getUser(w http.ResponseWriter, req *http.Request)   {}
+func serveFile(w http.ResponseWriter, req *http.Request) {}
+
+func requestLog(next http.Handler) http.Handler { return next }
+func auth(next http.Handler) http.Handler       { return next }
+func audit(next http.Handler) http.Handler      { return next }
+
+func walkRouter() *Router {
+	r := New()
+	r.AccessLog = false
+	r.Use(requestLog)
+	r.Get("/users", listUsers).Name("users")
+	r.Group("/admin", func(gr *Router) {
+		gr.Use(auth)
+		gr.Handle([]string{http.MethodGet, http.MethodPut}, "/users/{id:int}", getUser, audit).Name("user")
+	})
+	r.Get("/files/*path", serveFile)
+	return r
+}
+
+func TestWalk(t *testing.T) {
+	var got []string
+	err := walkRouter().Walk(func(ri RouteInfo) error {
+		got = append(got, ri.Method+" "+ri.Pattern)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"GET /users", "GET /admin/users/{id:int}", "PUT /admin/users/{id:int}", "GET /files/*path"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk order = %v, want %v", got, want)
+	}
+
+	var user RouteInfo
+	walkRouter().Walk(func(ri RouteInfo) error {
+		if ri.Name == "user" {
+			user = ri
+		}
+		return nil
+	})
+	pkg := "github.com/he-end/simproute/routes."
+	if user.Template != "/admin/users/{id}" || !reflect.DeepEqual(user.ParamNames, []string{"id"}) ||
+		!reflect.DeepEqual(user.ParamConstraints, []string{"int"}) || !reflect.DeepEqual(user.ParamCatchAll, []bool{false}) {
+		t.Errorf("params of user = %+v", user)
+	}
+	if user.Handler != pkg+"getUser" || !reflect.DeepEqual(user.Middlewares, []string{pkg + "requestLog", pkg + "auth", pkg + "audit"}) {
+		t.Errorf("handler %s, middleware %v", user.Handler, user.Middlewares)
+	}
+}
+
+func TestWalkStops(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := walkRouter().Walk(func(ri RouteInfo) error {
+		calls++
+		if ri.Method == http.MethodPut {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || calls != 3 {
+		t.Errorf("Walk = %v after %d calls, want stop after 3", err, calls)
+	}
+}
+
+func TestPrintRoutes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := walkRouter().PrintRoutes(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join("testdata", "routes.txt")
+	if *update {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run go test ./routes -run TestPrintRoutes -update", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("PrintRoutes =\n%s\nwant\n%s", buf.Bytes(), want)
+	}
+}
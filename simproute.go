@@ -4,6 +4,6 @@ import "github.com/he-end/simproute/routes"
 
 type Routes = routes.Router
 
-func NewRouter() *Routes {
-	return routes.New()
+func NewRouter(opts ...routes.Option) *Routes {
+	return routes.New(opts...)
 }
@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// SpanKind tells whether the span serves or sends a request
+type SpanKind string
+
+const (
+	SpanKindServer   SpanKind = "server"
+	SpanKindClient   SpanKind = "client"
+	SpanKindInternal SpanKind = "internal"
+)
+
+// Status of a finished span
+type Status string
+
+const (
+	StatusUnset Status = "unset"
+	StatusOK    Status = "ok"
+	StatusError Status = "error"
+)
+
+// Span is one timed operation of a trace. Exported fields are what the
+// Exporter receives, change them through the methods while the span runs.
+type Span struct {
+	Name          string         `json:"name"`
+	Kind          SpanKind       `json:"kind"`
+	TraceID       TraceID        `json:"trace_id"`
+	SpanID        SpanID         `json:"span_id"`
+	ParentSpanID  SpanID         `json:"parent_span_id,omitempty"`
+	TraceState    string         `json:"trace_state,omitempty"`
+	Flags         byte           `json:"flags"`
+	StartTime     time.Time      `json:"start"`
+	EndTime       time.Time      `json:"end"`
+	Status        Status         `json:"status"`
+	StatusMessage string         `json:"status_message,omitempty"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+
+	mu     sync.Mutex
+	tracer *Tracer
+	ended  bool
+}
+
+// MarshalJSON writes the span with parent_span_id left out for a root span,
+// omitempty has no effect on the SpanID array. The caller holds s.mu.
+func (s *Span) MarshalJSON() ([]byte, error) {
+	type plain Span
+	var parent *SpanID
+	if s.ParentSpanID.IsValid() {
+		parent = &s.ParentSpanID
+	}
+	return json.Marshal(struct {
+		*plain
+		ParentSpanID *SpanID `json:"parent_span_id,omitempty"`
+	}{(*plain)(s), parent})
+}
+
+// SpanContext returns the context to propagate to child spans and services
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return SpanContext{TraceID: s.TraceID, SpanID: s.SpanID, Flags: s.Flags, TraceState: s.TraceState}
+}
+
+// SetName renames the span, e.g. once the route is known
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.Name = name
+	s.mu.Unlock()
+}
+
+// SetAttribute records a key/value on the span
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]any)
+	}
+	s.Attributes[key] = value
+	s.mu.Unlock()
+}
+
+// SetStatus sets the final status, message is kept only for StatusError
+func (s *Span) SetStatus(status Status, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.Status = status
+	s.StatusMessage = ""
+	if status == StatusError {
+		s.StatusMessage = message
+	}
+	s.mu.Unlock()
+}
+
+// End finishes the span and hands it to the exporter, later calls do nothing
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+
+	if s.tracer != nil {
+		s.tracer.export(s)
+	}
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the current span, nil if there is none.
+// The Span methods accept a nil span.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
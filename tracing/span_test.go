@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSpanJSONParent(t *testing.T) {
+	var buf bytes.Buffer
+	tr := NewTracer(NewJSONLinesExporter(&buf))
+
+	ctx, root := tr.Start(context.Background(), "root", SpanKindServer, SpanContext{})
+	_, child := Start(ctx, "child")
+	child.End()
+	root.End()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("exported %d lines, want 2", len(lines))
+	}
+	var gotChild, gotRoot map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &gotChild); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &gotRoot); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := gotRoot["parent_span_id"]; ok {
+		t.Errorf("root span has parent_span_id: %s", lines[1])
+	}
+	if gotChild["parent_span_id"] != root.SpanID.String() {
+		t.Errorf("child parent_span_id = %v, want %s", gotChild["parent_span_id"], root.SpanID)
+	}
+	if gotChild["trace_id"] != root.TraceID.String() || gotChild["name"] != "child" {
+		t.Errorf("child span fields lost: %s", lines[0])
+	}
+}
+
+// an unsampled remote parent is continued but not exported
+func TestSampledBit(t *testing.T) {
+	remote, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		remote SpanContext
+		export bool
+	}{
+		{"new trace", SpanContext{}, true},
+		{"sampled parent", SpanContext{TraceID: remote.TraceID, SpanID: remote.SpanID, Flags: FlagSampled}, true},
+		{"unsampled parent", remote, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := NewInMemoryExporter()
+			ctx, root := NewTracer(mem).Start(context.Background(), "root", SpanKindServer, tt.remote)
+			_, child := Start(ctx, "child")
+			if root.SpanContext().Sampled() != tt.export || child.SpanContext().Sampled() != tt.export {
+				t.Errorf("sampled = %v, %v, want %v", root.SpanContext().Sampled(), child.SpanContext().Sampled(), tt.export)
+			}
+			if tt.remote.IsValid() && root.TraceID != tt.remote.TraceID {
+				t.Errorf("trace id = %s, want %s", root.TraceID, tt.remote.TraceID)
+			}
+			child.End()
+			root.End()
+			want := 0
+			if tt.export {
+				want = 2
+			}
+			if got := len(mem.Spans()); got != want {
+				t.Errorf("exported %d spans, want %d", got, want)
+			}
+		})
+	}
+}
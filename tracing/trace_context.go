@@ -0,0 +1,174 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context header names
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+)
+
+// ErrInvalidTraceparent is returned by ParseTraceparent for a malformed header
+var ErrInvalidTraceparent = errors.New("tracing: invalid traceparent")
+
+// TraceID identifies a whole trace, all zero is invalid
+type TraceID [16]byte
+
+// SpanID identifies one span of a trace, all zero is invalid
+type SpanID [8]byte
+
+func (t TraceID) IsValid() bool  { return t != TraceID{} }
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// MarshalText writes the id as lowercase hex, used by the JSON exporter
+func (t TraceID) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+func (s SpanID) IsValid() bool  { return s != SpanID{} }
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// MarshalText writes the id as lowercase hex, empty for an invalid id
+func (s SpanID) MarshalText() ([]byte, error) {
+	if !s.IsValid() {
+		return []byte{}, nil
+	}
+	return []byte(s.String()), nil
+}
+
+// FlagSampled is the sampled bit of the trace flags
+const FlagSampled byte = 0x01
+
+// SpanContext is the part of a span that crosses process boundaries
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+}
+
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+func (sc SpanContext) Sampled() bool { return sc.Flags&FlagSampled != 0 }
+
+// Traceparent formats sc as a version 00 traceparent header value
+func (sc SpanContext) Traceparent() string {
+	var b strings.Builder
+	b.Grow(55)
+	b.WriteString("00-")
+	b.WriteString(sc.TraceID.String())
+	b.WriteByte('-')
+	b.WriteString(sc.SpanID.String())
+	b.WriteByte('-')
+	b.WriteString(hex.EncodeToString([]byte{sc.Flags}))
+	return b.String()
+}
+
+// ParseTraceparent parses "<version>-<trace id>-<parent id>-<flags>".
+// Future versions may append fields, only the first four are read.
+func ParseTraceparent(v string) (SpanContext, error) {
+	var sc SpanContext
+	v = strings.TrimSpace(v)
+	if len(v) < 55 || v[2] != '-' || v[35] != '-' || v[52] != '-' {
+		return sc, ErrInvalidTraceparent
+	}
+	version := v[0:2]
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(v) != 55) {
+		return sc, ErrInvalidTraceparent
+	}
+	if len(v) > 55 && v[55] != '-' {
+		return sc, ErrInvalidTraceparent
+	}
+	if !isLowerHex(v[3:35]) || !isLowerHex(v[36:52]) || !isLowerHex(v[53:55]) {
+		return sc, ErrInvalidTraceparent
+	}
+
+	var flags [1]byte
+	hex.Decode(sc.TraceID[:], []byte(v[3:35]))
+	hex.Decode(sc.SpanID[:], []byte(v[36:52]))
+	hex.Decode(flags[:], []byte(v[53:55]))
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// normalizeTracestate drops empty members and returns "" when the list is
+// invalid, the header is then not propagated (W3C Trace Context 3.3)
+func normalizeTracestate(v string) string {
+	if v == "" || len(v) > 512 {
+		return ""
+	}
+	members := make([]string, 0, 4)
+	for _, m := range strings.Split(v, ",") {
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		eq := strings.IndexByte(m, '=')
+		if eq <= 0 || eq == len(m)-1 {
+			return ""
+		}
+		members = append(members, m)
+	}
+	if len(members) > 32 {
+		return ""
+	}
+	return strings.Join(members, ",")
+}
+
+// Extract reads the parent span context from traceparent and tracestate
+func Extract(h http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceparent(h.Get(HeaderTraceparent))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = normalizeTracestate(strings.Join(h.Values(HeaderTracestate), ","))
+	return sc, true
+}
+
+// Inject writes sc as traceparent and tracestate, use it on outgoing requests
+// with the span of the current request:
+//
+//	tracing.Inject(tracing.SpanFromContext(ctx).SpanContext(), outReq.Header)
+func Inject(sc SpanContext, h http.Header) {
+	if !sc.IsValid() {
+		return
+	}
+	h.Set(HeaderTraceparent, sc.Traceparent())
+	if sc.TraceState != "" {
+		h.Set(HeaderTracestate, sc.TraceState)
+	} else {
+		h.Del(HeaderTracestate)
+	}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
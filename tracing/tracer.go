@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/he-end/simproute/route_logger"
+	"go.uber.org/zap"
+)
+
+// Exporter receives every finished span of a sampled trace
+type Exporter interface {
+	ExportSpan(span *Span) error
+}
+
+// Tracer creates spans and sends them to its exporter
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer returns a tracer exporting to exp
+//
+// Example:
+//
+//	exp, _ := tracing.NewJSONLinesFileExporter("logs/spans.jsonl")
+//	r.Tracer = tracing.NewTracer(exp)
+func NewTracer(exp Exporter) *Tracer {
+	return &Tracer{exporter: exp}
+}
+
+// Start begins a span. The parent is the span in ctx, or remote when ctx has
+// none (e.g. from Extract), otherwise a new sampled trace is started. The
+// span keeps the sampled bit of its parent, an unsampled span still
+// propagates its context but is not exported.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind, remote SpanContext) (context.Context, *Span) {
+	span := &Span{
+		Name:      name,
+		Kind:      kind,
+		SpanID:    newSpanID(),
+		StartTime: time.Now(),
+		Status:    StatusUnset,
+		tracer:    t,
+	}
+
+	parent := SpanFromContext(ctx).SpanContext()
+	if !parent.IsValid() {
+		parent = remote
+	}
+	if parent.IsValid() {
+		span.TraceID = parent.TraceID
+		span.ParentSpanID = parent.SpanID
+		span.Flags = parent.Flags
+		span.TraceState = parent.TraceState
+	} else {
+		span.TraceID = newTraceID()
+		span.Flags = FlagSampled
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// Start begins a child span of the span in ctx with the same tracer,
+// it returns a nil span (safe to use) when ctx has no span
+//
+// Example:
+//
+//	ctx, span := tracing.Start(r.Context(), "load user")
+//	defer span.End()
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil || parent.tracer == nil {
+		return ctx, nil
+	}
+	return parent.tracer.Start(ctx, name, SpanKindInternal, SpanContext{})
+}
+
+func (t *Tracer) export(span *Span) {
+	if t.exporter == nil || span.Flags&FlagSampled == 0 {
+		return
+	}
+	if err := t.exporter.ExportSpan(span); err != nil {
+		logger.GetLogger().Warn("span export failed", zap.Error(err), zap.String("trace_id", span.TraceID.String()))
+	}
+}